@@ -0,0 +1,132 @@
+package parser
+
+const arenaChunkSize = 64
+
+// arenaSpan is an arena slot for a span. The node buffer is kept outside the
+// span so spans left empty can have nil Nodes like freshly allocated ones.
+type arenaSpan struct {
+	Span
+	nodes []Node
+}
+
+type arenaEmote struct {
+	Emote
+	modifiers []string
+}
+
+// nodeArena hands out nodes from chunks that are reused after reset. Pointers
+// into a chunk stay valid until the arena is reset since chunks never grow.
+type nodeArena struct {
+	spans    [][]arenaSpan
+	emotes   [][]arenaEmote
+	nicks    [][]Nick
+	tags     [][]Tag
+	emojis   [][]Emoji
+	channels [][]ChannelRef
+
+	nspans, nemotes, nnicks, ntags, nemojis, nchannels int
+}
+
+func (a *nodeArena) reset() {
+	a.nspans = 0
+	a.nemotes = 0
+	a.nnicks = 0
+	a.ntags = 0
+	a.nemojis = 0
+	a.nchannels = 0
+}
+
+// finish sets the empty slices of the nodes handed out since reset to nil
+// and keeps the buffers that grew for reuse.
+func (a *nodeArena) finish() {
+	for k := 0; k < a.nspans; k++ {
+		s := &a.spans[k/arenaChunkSize][k%arenaChunkSize]
+		if cap(s.Nodes) > cap(s.nodes) {
+			s.nodes = s.Nodes[:0]
+		}
+		if len(s.Nodes) == 0 {
+			s.Nodes = nil
+		}
+	}
+	for k := 0; k < a.nemotes; k++ {
+		e := &a.emotes[k/arenaChunkSize][k%arenaChunkSize]
+		if cap(e.Modifiers) > cap(e.modifiers) {
+			e.modifiers = e.Modifiers[:0]
+		}
+		if len(e.Modifiers) == 0 {
+			e.Modifiers = nil
+		}
+	}
+}
+
+func (a *nodeArena) span() *Span {
+	c, i := a.nspans/arenaChunkSize, a.nspans%arenaChunkSize
+	if c == len(a.spans) {
+		a.spans = append(a.spans, make([]arenaSpan, arenaChunkSize))
+	}
+	a.nspans++
+
+	s := &a.spans[c][i]
+	s.Span = Span{Nodes: s.nodes[:0]}
+	return &s.Span
+}
+
+func (a *nodeArena) emote() *Emote {
+	c, i := a.nemotes/arenaChunkSize, a.nemotes%arenaChunkSize
+	if c == len(a.emotes) {
+		a.emotes = append(a.emotes, make([]arenaEmote, arenaChunkSize))
+	}
+	a.nemotes++
+
+	e := &a.emotes[c][i]
+	e.Emote = Emote{Modifiers: e.modifiers[:0]}
+	return &e.Emote
+}
+
+func (a *nodeArena) nick() *Nick {
+	c, i := a.nnicks/arenaChunkSize, a.nnicks%arenaChunkSize
+	if c == len(a.nicks) {
+		a.nicks = append(a.nicks, make([]Nick, arenaChunkSize))
+	}
+	a.nnicks++
+
+	n := &a.nicks[c][i]
+	*n = Nick{}
+	return n
+}
+
+func (a *nodeArena) tag() *Tag {
+	c, i := a.ntags/arenaChunkSize, a.ntags%arenaChunkSize
+	if c == len(a.tags) {
+		a.tags = append(a.tags, make([]Tag, arenaChunkSize))
+	}
+	a.ntags++
+
+	t := &a.tags[c][i]
+	*t = Tag{}
+	return t
+}
+
+func (a *nodeArena) emoji() *Emoji {
+	c, i := a.nemojis/arenaChunkSize, a.nemojis%arenaChunkSize
+	if c == len(a.emojis) {
+		a.emojis = append(a.emojis, make([]Emoji, arenaChunkSize))
+	}
+	a.nemojis++
+
+	e := &a.emojis[c][i]
+	*e = Emoji{}
+	return e
+}
+
+func (a *nodeArena) channel() *ChannelRef {
+	c, i := a.nchannels/arenaChunkSize, a.nchannels%arenaChunkSize
+	if c == len(a.channels) {
+		a.channels = append(a.channels, make([]ChannelRef, arenaChunkSize))
+	}
+	a.nchannels++
+
+	r := &a.channels[c][i]
+	*r = ChannelRef{}
+	return r
+}
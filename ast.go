@@ -34,6 +34,8 @@ type Span struct {
 	Nodes  []Node
 	TokPos int
 	TokEnd int
+	// Limited is set on messages that exceeded the context's parse limits.
+	Limited LimitFlag
 }
 
 func (s *Span) Insert(n Node) {
@@ -54,7 +56,10 @@ func (s *Span) End() int {
 }
 
 type Link struct {
-	URL    string
+	URL   string
+	Class LinkClass
+	// Tags lists the tags that precede the link in its message.
+	Tags   []string
 	TokPos int
 	TokEnd int
 }
@@ -70,12 +75,33 @@ func (l *Link) End() int {
 type Emote struct {
 	Name      string
 	Modifiers []string
-	TokPos    int
-	TokEnd    int
+	// ModifierArgs holds the arguments passed to each modifier in Modifiers.
+	// It is nil unless at least one modifier has arguments.
+	ModifierArgs [][]string
+	Rejected     []*RejectedModifier
+	TokPos       int
+	TokEnd       int
 }
 
 func (e *Emote) InsertModifier(m string) {
 	e.Modifiers = append(e.Modifiers, m)
+	if e.ModifierArgs != nil {
+		e.ModifierArgs = append(e.ModifierArgs, nil)
+	}
+}
+
+func (e *Emote) InsertModifierWithArgs(m string, args []string) {
+	if args != nil && e.ModifierArgs == nil {
+		e.ModifierArgs = make([][]string, len(e.Modifiers), len(e.Modifiers)+1)
+	}
+	e.InsertModifier(m)
+	if args != nil {
+		e.ModifierArgs[len(e.ModifierArgs)-1] = args
+	}
+}
+
+func (e *Emote) InsertRejected(r *RejectedModifier) {
+	e.Rejected = append(e.Rejected, r)
 }
 
 func (e *Emote) Pos() int {
@@ -86,6 +112,60 @@ func (e *Emote) End() int {
 	return e.TokEnd
 }
 
+type ModifierRejectReason int
+
+const (
+	ModifierBadArgs ModifierRejectReason = iota
+	ModifierNotAllowed
+	ModifierTooMany
+	ModifierExclusive
+	ModifierLimit
+)
+
+var modifierRejectReasonNames = map[ModifierRejectReason]string{
+	ModifierBadArgs:    "BadArgs",
+	ModifierNotAllowed: "NotAllowed",
+	ModifierTooMany:    "TooMany",
+	ModifierExclusive:  "Exclusive",
+	ModifierLimit:      "Limit",
+}
+
+func (r ModifierRejectReason) String() string {
+	return modifierRejectReasonNames[r]
+}
+
+// RejectedModifier is a known emote modifier that was dropped because it
+// broke one of the context's modifier rules.
+type RejectedModifier struct {
+	Name   string
+	Args   []string
+	Reason ModifierRejectReason
+	TokPos int
+	TokEnd int
+}
+
+func (r *RejectedModifier) Pos() int {
+	return r.TokPos
+}
+
+func (r *RejectedModifier) End() int {
+	return r.TokEnd
+}
+
+type Emoji struct {
+	Codepoints []rune
+	TokPos     int
+	TokEnd     int
+}
+
+func (e *Emoji) Pos() int {
+	return e.TokPos
+}
+
+func (e *Emoji) End() int {
+	return e.TokEnd
+}
+
 type Tag struct {
 	Name   string
 	TokPos int
@@ -100,6 +180,18 @@ func (t *Tag) End() int {
 	return t.TokEnd
 }
 
+// MessageTags returns the distinct tags used in a message in order of first
+// use.
+func MessageTags(msg *Span) (tags []string) {
+	Inspect(msg, func(n Node) bool {
+		if t, ok := n.(*Tag); ok && !containsString(tags, t.Name) {
+			tags = append(tags, t.Name)
+		}
+		return true
+	})
+	return
+}
+
 type Nick struct {
 	Nick   string
 	TokPos int
@@ -114,3 +206,34 @@ func (n *Nick) Pos() int {
 func (n *Nick) End() int {
 	return n.TokEnd
 }
+
+type ChannelRef struct {
+	Channel     string
+	DisplayName string
+	Live        bool
+	TokPos      int
+	TokEnd      int
+}
+
+func (c *ChannelRef) Pos() int {
+	return c.TokPos
+}
+
+func (c *ChannelRef) End() int {
+	return c.TokEnd
+}
+
+// Inspect traverses an AST in depth-first order. It starts by calling f(node);
+// if f returns true, Inspect invokes f recursively for each of the children of
+// node, followed by a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	if !f(node) {
+		return
+	}
+	if s, ok := node.(*Span); ok {
+		for _, n := range s.Nodes {
+			Inspect(n, f)
+		}
+	}
+	f(nil)
+}
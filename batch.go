@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// newBatchParser returns a parser that reuses its input buffer between
+// messages but allocates fresh nodes so results outlive the next Reset.
+func newBatchParser(ctx *ParserContext) *Parser {
+	return NewParser(ctx, lexer{})
+}
+
+func batchWorkers(workers int) int {
+	if workers <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return workers
+}
+
+// ParseBatch parses msgs on a pool of workers sharing ctx and returns their
+// ASTs in input order. A workers count of zero or less uses GOMAXPROCS. If c
+// is cancelled before every message is parsed the partial results are
+// discarded and c.Err() is returned.
+func ParseBatch(c context.Context, ctx *ParserContext, msgs []string, workers int) ([]*Span, error) {
+	workers = batchWorkers(workers)
+	if workers > len(msgs) {
+		workers = len(msgs)
+	}
+
+	results := make([]*Span, len(msgs))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			p := newBatchParser(ctx)
+			for j := range jobs {
+				p.Reset(msgs[j])
+				results[j] = p.ParseMessage()
+			}
+		}()
+	}
+
+	var err error
+dispatch:
+	for i := range msgs {
+		select {
+		case jobs <- i:
+		case <-c.Done():
+			err = c.Err()
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+type batchJob struct {
+	input  string
+	result chan *Span
+}
+
+// ParseStream parses the messages received from in on a pool of workers
+// sharing ctx and sends their ASTs to the returned channel in input order.
+// The output channel is closed once in is closed and drained or when c is
+// cancelled; callers distinguish the two with c.Err().
+func ParseStream(c context.Context, ctx *ParserContext, in <-chan string, workers int) <-chan *Span {
+	workers = batchWorkers(workers)
+
+	jobs := make(chan batchJob)
+	pending := make(chan chan *Span, workers)
+	out := make(chan *Span)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			p := newBatchParser(ctx)
+			for j := range jobs {
+				p.Reset(j.input)
+				j.result <- p.ParseMessage()
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+
+		for {
+			var input string
+			var ok bool
+			select {
+			case input, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-c.Done():
+				return
+			}
+
+			j := batchJob{input, make(chan *Span, 1)}
+			select {
+			case pending <- j.result:
+			case <-c.Done():
+				return
+			}
+			select {
+			case jobs <- j:
+			case <-c.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer close(out)
+
+		for r := range pending {
+			var s *Span
+			select {
+			case s = <-r:
+			case <-c.Done():
+				return
+			}
+			select {
+			case out <- s:
+			case <-c.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func parseSequential(ctx *ParserContext, msgs []string) []*Span {
+	spans := make([]*Span, len(msgs))
+	for i, m := range msgs {
+		spans[i] = NewParser(ctx, NewLexer(m)).ParseMessage()
+	}
+	return spans
+}
+
+func TestParseBatch(t *testing.T) {
+	ctx := newTestParserContext()
+	samples := readCorpus(300)
+	expected := parseSequential(ctx, samples)
+
+	for _, workers := range []int{0, 1, 4, 1000} {
+		spans, err := ParseBatch(context.Background(), ctx, samples, workers)
+		if err != nil {
+			t.Fatalf("%d workers: unexpected error %s", workers, err)
+		}
+		if !reflect.DeepEqual(expected, spans) {
+			t.Errorf("%d workers: batch results differ from sequential parse", workers)
+		}
+	}
+}
+
+func TestParseBatchCancel(t *testing.T) {
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	spans, err := ParseBatch(c, newTestParserContext(), readCorpus(300), 4)
+	if err != context.Canceled || spans != nil {
+		t.Errorf("expected cancellation, got %d results and error %v", len(spans), err)
+	}
+}
+
+func TestParseStream(t *testing.T) {
+	ctx := newTestParserContext()
+	samples := readCorpus(300)
+	expected := parseSequential(ctx, samples)
+
+	in := make(chan string)
+	go func() {
+		for _, s := range samples {
+			in <- s
+		}
+		close(in)
+	}()
+
+	var spans []*Span
+	for s := range ParseStream(context.Background(), ctx, in, 4) {
+		spans = append(spans, s)
+	}
+	if !reflect.DeepEqual(expected, spans) {
+		t.Errorf("stream results differ from sequential parse")
+	}
+}
+
+func TestParseStreamCancel(t *testing.T) {
+	c, cancel := context.WithCancel(context.Background())
+
+	in := make(chan string)
+	go func() {
+		for {
+			select {
+			case in <- "PEPE":
+			case <-c.Done():
+				return
+			}
+		}
+	}()
+
+	out := ParseStream(c, newTestParserContext(), in, 4)
+	for i := 0; i < 10; i++ {
+		<-out
+	}
+	cancel()
+	for range out {
+	}
+	if c.Err() != context.Canceled {
+		t.Errorf("expected context to be cancelled")
+	}
+}
+
+func BenchmarkParseBatch(b *testing.B) {
+	samples := readCorpus(884)
+	ctx := newTestParserContext()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseBatch(context.Background(), ctx, samples, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
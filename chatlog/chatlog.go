@@ -0,0 +1,157 @@
+// Package chatlog replays archived chat logs made of lines like
+//
+//	[2020-01-02 15:04:05 UTC] abeous: PEPE hi
+//
+// and parses each message with the nicks known at the time it was sent.
+package chatlog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	parser "github.com/MemeLabs/chat-parser"
+)
+
+// timeLayouts are the timestamp formats accepted between the brackets.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05.000 MST",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+}
+
+var ErrMalformedLine = errors.New("malformed log line")
+
+// maxLineBytes is the length of the longest line read as a log entry. Longer
+// lines are skipped.
+const maxLineBytes = 1 << 20
+
+// LineError reports a line that could not be read as a log entry. Reading
+// can continue after it.
+type LineError struct {
+	Line int
+	Text string
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Entry is a parsed log line.
+type Entry struct {
+	Line int
+	Time time.Time
+	Nick string
+	Data string
+	AST  *parser.Span
+}
+
+// NewReader returns a reader that parses the messages of the log in r with
+// ctx. Every nick is added to ctx.Nicks when it first speaks, before its
+// message is parsed, so later messages mention it and earlier ones do not.
+func NewReader(r io.Reader, ctx *parser.ParserContext) *Reader {
+	return &Reader{
+		r:   bufio.NewReader(r),
+		ctx: ctx,
+	}
+}
+
+type Reader struct {
+	r    *bufio.Reader
+	ctx  *parser.ParserContext
+	line int
+	buf  []byte
+}
+
+// Next returns the next entry. It returns io.EOF at the end of the log and a
+// *LineError for lines that are not log entries, including lines longer than
+// 1 MiB.
+func (r *Reader) Next() (*Entry, error) {
+	for {
+		text, long, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+		r.line++
+		if long {
+			return nil, &LineError{r.line, text, fmt.Errorf("%w: longer than %d bytes", ErrMalformedLine, maxLineBytes)}
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		e, err := parseLine(text)
+		if err != nil {
+			return nil, &LineError{r.line, text, err}
+		}
+		e.Line = r.line
+
+		r.ctx.Nicks.InsertNoReplace([]rune(e.Nick))
+		e.AST = parser.NewParser(r.ctx, parser.NewLexer(e.Data)).ParseMessage()
+		return e, nil
+	}
+}
+
+// readLine reads the next line without its line ending. The text of lines
+// longer than maxLineBytes is truncated to its first maxLineBytes bytes and
+// the rest of the line is discarded.
+func (r *Reader) readLine() (text string, long bool, err error) {
+	r.buf = r.buf[:0]
+	for {
+		b, more, err := r.r.ReadLine()
+		if err != nil {
+			return "", false, err
+		}
+		if n := maxLineBytes - len(r.buf); len(b) > n {
+			b = b[:n]
+			long = true
+		}
+		r.buf = append(r.buf, b...)
+		if !more {
+			return string(r.buf), long, nil
+		}
+	}
+}
+
+func parseLine(text string) (*Entry, error) {
+	if !strings.HasPrefix(text, "[") {
+		return nil, fmt.Errorf("%w: missing timestamp", ErrMalformedLine)
+	}
+	i := strings.Index(text, "] ")
+	if i == -1 {
+		return nil, fmt.Errorf("%w: unterminated timestamp", ErrMalformedLine)
+	}
+	t, err := parseTime(text[1:i])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedLine, err)
+	}
+
+	rest := text[i+2:]
+	j := strings.Index(rest, ": ")
+	if j <= 0 || strings.ContainsAny(rest[:j], " \t") {
+		return nil, fmt.Errorf("%w: missing nick", ErrMalformedLine)
+	}
+	return &Entry{
+		Time: t,
+		Nick: rest[:j],
+		Data: rest[j+2:],
+	}, nil
+}
+
+func parseTime(s string) (t time.Time, err error) {
+	for _, l := range timeLayouts {
+		if t, err = time.Parse(l, s); err == nil {
+			return
+		}
+	}
+	return
+}
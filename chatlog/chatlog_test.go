@@ -0,0 +1,144 @@
+package chatlog
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	parser "github.com/MemeLabs/chat-parser"
+)
+
+const testLog = `[2020-01-02 15:04:05 UTC] abeous: hi wrxst
+[2020-01-02 15:04:06 UTC] wrxst: PEPE abeous
+
+not a log line
+[2020-01-02T15:04:07Z] Abeous: wrxst PEPE
+[2020-01-02 15:04:08 UTC] nick without colon
+`
+
+func readAll(t *testing.T, r *Reader) (entries []*Entry, lines []int) {
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			return
+		}
+		var lerr *LineError
+		if errors.As(err, &lerr) {
+			if !errors.Is(err, ErrMalformedLine) {
+				t.Errorf("unexpected error %s", err)
+			}
+			lines = append(lines, lerr.Line)
+			continue
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+}
+
+func TestReader(t *testing.T) {
+	ctx := parser.NewParserContext(parser.ParserContextValues{
+		Emotes: []string{"PEPE"},
+	})
+	entries, malformed := readAll(t, NewReader(strings.NewReader(testLog), ctx))
+
+	if !reflect.DeepEqual(malformed, []int{4, 6}) {
+		t.Errorf("unexpected malformed lines %v", malformed)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+
+	expected := []*parser.Span{
+		// wrxst has not spoken yet.
+		{Type: parser.SpanMessage, TokEnd: 8},
+		{Type: parser.SpanMessage, Nodes: []parser.Node{
+			&parser.Emote{Name: "PEPE", TokPos: 0, TokEnd: 4},
+			&parser.Nick{Nick: "abeous", TokPos: 5, TokEnd: 11},
+		}, TokEnd: 11},
+		{Type: parser.SpanMessage, Nodes: []parser.Node{
+			&parser.Nick{Nick: "wrxst", TokPos: 0, TokEnd: 5},
+			&parser.Emote{Name: "PEPE", TokPos: 6, TokEnd: 10},
+		}, TokEnd: 10},
+	}
+	for i, e := range entries {
+		if !reflect.DeepEqual(expected[i], e.AST) {
+			t.Errorf("entry %d: unexpected AST %+v", i, e.AST)
+		}
+	}
+
+	if e := entries[2]; e.Line != 5 || e.Nick != "Abeous" || !e.Time.Equal(time.Date(2020, 1, 2, 15, 4, 7, 0, time.UTC)) {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+func TestReaderLongLines(t *testing.T) {
+	ctx := parser.NewParserContext(parser.ParserContextValues{})
+	entry := "[2020-01-02 15:04:05 UTC] abeous: "
+	log := entry + "hi\n" +
+		entry + strings.Repeat("a", maxLineBytes+1-len(entry)) + "\n" +
+		entry + strings.Repeat("a", maxLineBytes-len(entry)) + "\r\n" +
+		entry + "bye"
+	entries, malformed := readAll(t, NewReader(strings.NewReader(log), ctx))
+
+	if !reflect.DeepEqual(malformed, []int{2}) {
+		t.Errorf("unexpected malformed lines %v", malformed)
+	}
+	var lines []int
+	for _, e := range entries {
+		lines = append(lines, e.Line)
+	}
+	if !reflect.DeepEqual(lines, []int{1, 3, 4}) {
+		t.Fatalf("unexpected entry lines %v", lines)
+	}
+	if n := len(entries[1].Data); n != maxLineBytes-len(entry) {
+		t.Errorf("expected the longest line to be read whole, got %d bytes", n)
+	}
+	if entries[2].Data != "bye" {
+		t.Errorf("unexpected entry %+v", entries[2])
+	}
+}
+
+func TestEntryJSON(t *testing.T) {
+	ctx := parser.NewParserContext(parser.ParserContextValues{Emotes: []string{"PEPE"}})
+	e, err := NewReader(strings.NewReader("[2020-01-02 15:04:05 UTC] abeous: PEPE"), ctx).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"line":1,"time":"2020-01-02T15:04:05Z","nick":"abeous","data":"PEPE","ast":{"type":"Span","spanType":"Message","nodes":[{"type":"Emote","name":"PEPE","modifiers":[],"pos":0,"end":4}],"pos":0,"end":4}}`
+	if string(b) != expected {
+		t.Errorf("got %s expected %s", b, expected)
+	}
+}
+
+func TestStats(t *testing.T) {
+	ctx := parser.NewParserContext(parser.ParserContextValues{Emotes: []string{"PEPE"}})
+	entries, malformed := readAll(t, NewReader(strings.NewReader(testLog), ctx))
+
+	s := NewStats()
+	for _, e := range entries {
+		s.Add(e)
+	}
+	for range malformed {
+		s.AddMalformed()
+	}
+
+	if s.Messages != 3 || s.Malformed != 2 || s.Speakers != 2 {
+		t.Errorf("unexpected counts %+v", s)
+	}
+	if expected := map[string]int{"Message": 3, "Emote": 2, "Nick": 2}; !reflect.DeepEqual(expected, s.Nodes) {
+		t.Errorf("unexpected node counts %v", s.Nodes)
+	}
+	if s.Last.Sub(s.First) != 2*time.Second {
+		t.Errorf("unexpected range %s - %s", s.First, s.Last)
+	}
+}
@@ -0,0 +1,90 @@
+package chatlog
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	parser "github.com/MemeLabs/chat-parser"
+)
+
+type jsonEntry struct {
+	Line int             `json:"line"`
+	Time time.Time       `json:"time"`
+	Nick string          `json:"nick"`
+	Data string          `json:"data"`
+	AST  json.RawMessage `json:"ast"`
+}
+
+// MarshalJSON encodes the entry with its AST in the format of
+// parser.MarshalNodeJSON.
+func (e *Entry) MarshalJSON() ([]byte, error) {
+	ast, err := parser.MarshalNodeJSON(e.AST, nil)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonEntry{e.Line, e.Time, e.Nick, e.Data, ast})
+}
+
+func NewStats() *Stats {
+	return &Stats{
+		Nodes:    map[string]int{},
+		speakers: map[string]struct{}{},
+	}
+}
+
+// Stats summarizes a replayed log.
+type Stats struct {
+	Messages  int `json:"messages"`
+	Malformed int `json:"malformed"`
+	// Speakers is the number of distinct nicks that sent messages.
+	Speakers int `json:"speakers"`
+	// Nodes counts AST nodes by type. Spans are counted by span type.
+	Nodes map[string]int `json:"nodes"`
+	First time.Time      `json:"first"`
+	Last  time.Time      `json:"last"`
+
+	speakers map[string]struct{}
+}
+
+func (s *Stats) Add(e *Entry) {
+	s.Messages++
+	if s.First.IsZero() || e.Time.Before(s.First) {
+		s.First = e.Time
+	}
+	if e.Time.After(s.Last) {
+		s.Last = e.Time
+	}
+
+	key := strings.ToLower(e.Nick)
+	if _, ok := s.speakers[key]; !ok {
+		s.speakers[key] = struct{}{}
+		s.Speakers++
+	}
+
+	parser.Inspect(e.AST, func(n parser.Node) bool {
+		switch n := n.(type) {
+		case nil:
+		case *parser.Span:
+			s.Nodes[n.Type.String()]++
+		case *parser.Emote:
+			s.Nodes["Emote"]++
+		case *parser.Nick:
+			s.Nodes["Nick"]++
+		case *parser.Tag:
+			s.Nodes["Tag"]++
+		case *parser.Link:
+			s.Nodes["Link"]++
+		case *parser.Emoji:
+			s.Nodes["Emoji"]++
+		case *parser.ChannelRef:
+			s.Nodes["ChannelRef"]++
+		}
+		return true
+	})
+}
+
+// AddMalformed counts a line that could not be read.
+func (s *Stats) AddMalformed() {
+	s.Malformed++
+}
@@ -0,0 +1,113 @@
+// Command chatlog replays chat logs and writes the parsed messages as JSON
+// lines, or aggregate statistics with -stats.
+//
+//	chatlog -emotes PEPE,CuckCrab -tags nsfw,nsfl logs/2020-01-*.txt
+//
+// Logs are read from stdin when no files are given. Malformed lines are
+// reported on stderr and skipped.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	parser "github.com/MemeLabs/chat-parser"
+	"github.com/MemeLabs/chat-parser/chatlog"
+)
+
+var (
+	emotes    = flag.String("emotes", "", "comma separated emotes")
+	modifiers = flag.String("modifiers", "", "comma separated emote modifiers")
+	nicks     = flag.String("nicks", "", "comma separated nicks known before the log starts")
+	tags      = flag.String("tags", "", "comma separated tags")
+	stats     = flag.Bool("stats", false, "write aggregate statistics instead of messages")
+)
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func main() {
+	flag.Parse()
+	log.SetFlags(0)
+
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run replays the logs named on the command line. Output written before an
+// error is flushed before the error is returned.
+func run() (err error) {
+	ctx := parser.NewParserContext(parser.ParserContextValues{
+		Emotes:         split(*emotes),
+		EmoteModifiers: split(*modifiers),
+		Nicks:          split(*nicks),
+		Tags:           split(*tags),
+	})
+
+	w := bufio.NewWriter(os.Stdout)
+	defer func() {
+		if ferr := w.Flush(); err == nil {
+			err = ferr
+		}
+	}()
+	enc := json.NewEncoder(w)
+	s := chatlog.NewStats()
+
+	replay := func(name string, r io.Reader) error {
+		lr := chatlog.NewReader(r, ctx)
+		for {
+			e, err := lr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			var lerr *chatlog.LineError
+			if errors.As(err, &lerr) {
+				log.Printf("%s: %s", name, err)
+				s.AddMalformed()
+				continue
+			} else if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+
+			if *stats {
+				s.Add(e)
+			} else if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+	}
+
+	if flag.NArg() == 0 {
+		if err := replay("stdin", os.Stdin); err != nil {
+			return err
+		}
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = replay(name, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if *stats {
+		return enc.Encode(s)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"sync"
+	"unicode"
+)
+
+// Combo is a run of consecutive messages from different nicks that contain
+// only the same emote.
+type Combo struct {
+	Emote string
+	Count int
+	Nicks []string
+}
+
+func NewComboTracker() *ComboTracker {
+	return &ComboTracker{}
+}
+
+// ComboTracker follows the messages of a single channel in order and detects
+// emote combos.
+type ComboTracker struct {
+	sync.Mutex
+	emote string
+	nicks []string
+}
+
+// Push records a message. If the message contains nothing but an emote the
+// resulting combo is returned; a Count greater than one means the message
+// continued a combo. Any other message ends the current combo and returns nil.
+// Repeats from a nick already in the combo neither extend nor end it.
+func (c *ComboTracker) Push(nick, input string, msg *Span) *Combo {
+	c.Lock()
+	defer c.Unlock()
+
+	e := singleEmote([]rune(input), msg)
+	if e == nil {
+		c.emote = ""
+		c.nicks = c.nicks[:0]
+		return nil
+	}
+
+	if e.Name != c.emote {
+		c.emote = e.Name
+		c.nicks = c.nicks[:0]
+	} else if containsString(c.nicks, nick) {
+		return nil
+	}
+	c.nicks = append(c.nicks, nick)
+
+	return c.combo()
+}
+
+// Current returns the combo in progress or nil if there is none.
+func (c *ComboTracker) Current() *Combo {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.emote == "" {
+		return nil
+	}
+	return c.combo()
+}
+
+// Reset ends the current combo.
+func (c *ComboTracker) Reset() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.emote = ""
+	c.nicks = c.nicks[:0]
+}
+
+func (c *ComboTracker) combo() *Combo {
+	return &Combo{
+		Emote: c.emote,
+		Count: len(c.nicks),
+		Nicks: append([]string(nil), c.nicks...),
+	}
+}
+
+// singleEmote returns the emote in msg if it is the only thing in the message
+// besides whitespace.
+func singleEmote(input []rune, msg *Span) *Emote {
+	if msg == nil || msg.Type != SpanMessage || len(msg.Nodes) != 1 {
+		return nil
+	}
+	e, ok := msg.Nodes[0].(*Emote)
+	if !ok {
+		return nil
+	}
+
+	for i, r := range input {
+		if (i < e.TokPos || i >= e.TokEnd) && !unicode.IsSpace(r) {
+			return nil
+		}
+	}
+	return e
+}
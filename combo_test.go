@@ -0,0 +1,40 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComboTracker(t *testing.T) {
+	ctx := NewParserContext(ParserContextValues{
+		Emotes:         []string{"PEPE", "CuckCrab"},
+		EmoteModifiers: []string{"wide"},
+	})
+
+	messages := []struct {
+		nick     string
+		input    string
+		expected *Combo
+	}{
+		{"a", "PEPE", &Combo{Emote: "PEPE", Count: 1, Nicks: []string{"a"}}},
+		{"b", " PEPE:wide ", &Combo{Emote: "PEPE", Count: 2, Nicks: []string{"a", "b"}}},
+		{"b", "PEPE", nil},
+		{"c", "PEPE", &Combo{Emote: "PEPE", Count: 3, Nicks: []string{"a", "b", "c"}}},
+		{"d", "CuckCrab", &Combo{Emote: "CuckCrab", Count: 1, Nicks: []string{"d"}}},
+		{"a", "CuckCrab", &Combo{Emote: "CuckCrab", Count: 2, Nicks: []string{"d", "a"}}},
+		{"b", "CuckCrab lol", nil},
+		{"c", "CuckCrab", &Combo{Emote: "CuckCrab", Count: 1, Nicks: []string{"c"}}},
+		{"d", "PEPE PEPE", nil},
+		{"e", "PEPE", &Combo{Emote: "PEPE", Count: 1, Nicks: []string{"e"}}},
+	}
+
+	c := NewComboTracker()
+	for i, m := range messages {
+		p := NewParser(ctx, NewLexer(m.input))
+		combo := c.Push(m.nick, m.input, p.ParseMessage())
+
+		if !reflect.DeepEqual(m.expected, combo) {
+			t.Errorf("message %d %q: got %+v expected %+v", i, m.input, combo, m.expected)
+		}
+	}
+}
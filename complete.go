@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+type CompletionKind int
+
+const (
+	CompletionNone CompletionKind = iota
+	CompletionEmote
+	CompletionModifier
+	CompletionNick
+	CompletionCommand
+	CompletionShortcode
+	CompletionChannel
+)
+
+var completionKindNames = map[CompletionKind]string{
+	CompletionNone:      "None",
+	CompletionEmote:     "Emote",
+	CompletionModifier:  "Modifier",
+	CompletionNick:      "Nick",
+	CompletionCommand:   "Command",
+	CompletionShortcode: "Shortcode",
+	CompletionChannel:   "Channel",
+}
+
+func (k CompletionKind) String() string {
+	return completionKindNames[k]
+}
+
+// Completion describes what the word at the cursor can be completed to. A
+// candidate replaces input[TokPos:TokEnd], which spans the whole word under
+// the cursor and excludes sigils like @ or the colon before a modifier.
+type Completion struct {
+	Kind       CompletionKind
+	Prefix     string
+	TokPos     int
+	TokEnd     int
+	Candidates []string
+}
+
+// Complete returns the completion for the word at cursor, a rune offset into
+// input. Bare words complete to emotes or, if none match, to nicks. Nothing
+// is completed inside code spans or after whitespace.
+func Complete(ctx *ParserContext, input string, cursor int) Completion {
+	runes := []rune(input)
+	if cursor < 0 {
+		cursor = 0
+	} else if cursor > len(runes) {
+		cursor = len(runes)
+	}
+	c := Completion{TokPos: cursor, TokEnd: cursor}
+
+	msg := NewParser(ctx, NewLexer(input)).ParseMessage()
+	if inCodeSpan(runes, msg, cursor) {
+		return c
+	}
+
+	toks := completionTokens(input)
+	prev := -1
+	word := false
+	for i, t := range toks {
+		end := t.pos + len(t.val)
+		if t.typ == tokWord && t.pos < cursor && cursor <= end {
+			c.TokPos, c.TokEnd = t.pos, end
+			c.Prefix = string(t.val[:cursor-t.pos])
+			prev = i - 1
+			word = true
+			break
+		}
+		if end == cursor && len(t.val) != 0 {
+			prev = i
+		}
+	}
+
+	var p token
+	if prev >= 0 {
+		p = toks[prev]
+	}
+	prefix := []rune(c.Prefix)
+
+	switch {
+	case prev >= 0 && p.typ == tokAt:
+		c.Kind = CompletionNick
+		c.Candidates = ctx.Nicks.MatchPrefix(prefix)
+	case prev >= 0 && p.typ == tokHash && ctx.Channels != nil:
+		c.Kind = CompletionChannel
+		c.Candidates = ctx.Channels.MatchPrefix(prefix)
+	case prev == 0 && p.typ == tokRSlash && ctx.Commands != nil:
+		c.Kind = CompletionCommand
+		c.Candidates = ctx.Commands.MatchPrefix(prefix)
+	case prev >= 0 && p.typ == tokColon:
+		if emoteContains(msg, p.pos) {
+			c.Kind = CompletionModifier
+			c.Candidates = ctx.EmoteModifiers.MatchPrefix(prefix)
+		} else if ctx.Shortcodes != nil && (prev == 0 || toks[prev-1].typ == tokWhitespace) {
+			c.Kind = CompletionShortcode
+			c.Candidates = ctx.Shortcodes.MatchPrefix(prefix)
+		}
+	case word:
+		c.Kind = CompletionEmote
+		c.Candidates = ctx.Emotes.MatchPrefix(prefix)
+		if len(c.Candidates) == 0 {
+			if nicks := ctx.Nicks.MatchPrefix(prefix); len(nicks) != 0 {
+				c.Kind = CompletionNick
+				c.Candidates = nicks
+			}
+		}
+	}
+
+	sortCandidates(c.Candidates)
+	return c
+}
+
+func completionTokens(input string) (toks []token) {
+	l := NewLexer(input)
+	for {
+		t := l.Next()
+		if t.typ == tokEOF {
+			return
+		}
+		toks = append(toks, t)
+	}
+}
+
+// inCodeSpan reports whether cursor lies between the markers of a code span.
+func inCodeSpan(input []rune, msg *Span, cursor int) (ok bool) {
+	Inspect(msg, func(n Node) bool {
+		s, isSpan := n.(*Span)
+		if !isSpan || ok {
+			return false
+		}
+		if s.Type == SpanCode {
+			open, close := spanMarkers(input, s)
+			ok = open <= cursor && cursor <= close
+			return false
+		}
+		return true
+	})
+	return
+}
+
+// emoteContains reports whether the colon at pos follows an emote or one of
+// its modifiers.
+func emoteContains(msg *Span, pos int) (ok bool) {
+	Inspect(msg, func(n Node) bool {
+		if e, isEmote := n.(*Emote); isEmote && e.TokPos < pos && pos <= e.TokEnd {
+			ok = true
+		}
+		_, isSpan := n.(*Span)
+		return isSpan && !ok
+	})
+	return
+}
+
+// sortCandidates orders candidates alphabetically ignoring case.
+func sortCandidates(c []string) {
+	sort.Slice(c, func(i, j int) bool {
+		a, b := strings.ToLower(c[i]), strings.ToLower(c[j])
+		if a != b {
+			return a < b
+		}
+		return c[i] < c[j]
+	})
+}
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	ctx := newTestParserContext()
+	ctx.Emotes.Insert([]rune("PEPEHands"))
+	ctx.Commands = NewRuneIndex(RunesFromStrings([]string{"me", "mute", "whisper"}))
+
+	cases := []struct {
+		name     string
+		input    string
+		expected Completion
+	}{
+		{"emote", "hi pe|", Completion{CompletionEmote, "pe", 3, 5, []string{"PEPE", "PEPEHands"}}},
+		{"middle of word", "PE|PEH x", Completion{CompletionEmote, "PE", 0, 5, []string{"PEPE", "PEPEHands"}}},
+		{"bare nick", "wr|", Completion{CompletionNick, "wr", 0, 2, []string{"wrxst"}}},
+		{"nick", "hi @ab|", Completion{CompletionNick, "ab", 4, 6, []string{"abeous"}}},
+		{"empty nick", "@|", Completion{CompletionNick, "", 1, 1, []string{"abeous", "jeanpierrepratt", "wrxst"}}},
+		{"modifier", "PEPE:w|", Completion{CompletionModifier, "w", 5, 6, []string{"wide"}}},
+		{"second modifier", "PEPE:wide:|", Completion{CompletionModifier, "", 10, 10, []string{"rustle", "spin", "wide"}}},
+		{"command", "/m|", Completion{CompletionCommand, "m", 1, 2, []string{"me", "mute"}}},
+		{"not a command", "a /m|", Completion{CompletionEmote, "m", 3, 4, nil}},
+		{"shortcode", "ok :thu|", Completion{CompletionShortcode, "thu", 4, 7, []string{"thumbsup"}}},
+		{"channel", "#st|", Completion{CompletionChannel, "st", 1, 3, []string{"strims"}}},
+		{"code", "`pe|`", Completion{CompletionNone, "", 3, 3, nil}},
+		{"unclosed code", "`pe|", Completion{CompletionNone, "", 3, 3, nil}},
+		{"after code", "`x` pe|", Completion{CompletionEmote, "pe", 4, 6, []string{"PEPE", "PEPEHands"}}},
+		{"whitespace", "PEPE |", Completion{CompletionNone, "", 5, 5, nil}},
+	}
+
+	for _, c := range cases {
+		cursor := len([]rune(c.input[:strings.Index(c.input, "|")]))
+		input := strings.Replace(c.input, "|", "", 1)
+
+		if got := Complete(ctx, input, cursor); !reflect.DeepEqual(c.expected, got) {
+			t.Errorf("%s: got %+v expected %+v", c.name, got, c.expected)
+		}
+	}
+}
+
+func TestMatchPrefix(t *testing.T) {
+	ctx := newTestParserContext()
+
+	if m := ctx.Nicks.MatchPrefix([]rune("JEAN")); !reflect.DeepEqual(m, []string{"jeanpierrepratt"}) {
+		t.Errorf("unexpected nick matches %v", m)
+	}
+	if m := ctx.Emotes.MatchPrefix([]rune("cuck")); !reflect.DeepEqual(m, []string{"CuckCrab"}) {
+		t.Errorf("unexpected emote matches %v", m)
+	}
+	if m := ctx.Tags.MatchPrefix([]rune("x")); m != nil {
+		t.Errorf("unexpected tag matches %v", m)
+	}
+}
@@ -0,0 +1,312 @@
+package parser
+
+import "unicode"
+
+const (
+	zeroWidthJoiner    rune = 0x200d
+	variationSelector  rune = 0xfe0f
+	combiningKeycap    rune = 0x20e3
+	tagCancel          rune = 0xe007f
+	regionalIndicatorA rune = 0x1f1e6
+	regionalIndicatorZ rune = 0x1f1ff
+)
+
+// emojiPresentation contains the characters with the Emoji_Presentation
+// property from UTR #51. These are rendered as emoji without a variation
+// selector.
+var emojiPresentation = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x231a, 0x231b, 1},
+		{0x23e9, 0x23ec, 1},
+		{0x23f0, 0x23f3, 3},
+		{0x25fd, 0x25fe, 1},
+		{0x2614, 0x2615, 1},
+		{0x2648, 0x2653, 1},
+		{0x267f, 0x2693, 20},
+		{0x26a1, 0x26a1, 1},
+		{0x26aa, 0x26ab, 1},
+		{0x26bd, 0x26be, 1},
+		{0x26c4, 0x26c5, 1},
+		{0x26ce, 0x26d4, 6},
+		{0x26ea, 0x26ea, 1},
+		{0x26f2, 0x26f3, 1},
+		{0x26f5, 0x26fa, 5},
+		{0x26fd, 0x26fd, 1},
+		{0x2705, 0x2705, 1},
+		{0x270a, 0x270b, 1},
+		{0x2728, 0x2728, 1},
+		{0x274c, 0x274e, 2},
+		{0x2753, 0x2755, 1},
+		{0x2757, 0x2757, 1},
+		{0x2795, 0x2797, 1},
+		{0x27b0, 0x27bf, 15},
+		{0x2b1b, 0x2b1c, 1},
+		{0x2b50, 0x2b55, 5},
+	},
+	R32: []unicode.Range32{
+		{0x1f004, 0x1f0cf, 203},
+		{0x1f18e, 0x1f18e, 1},
+		{0x1f191, 0x1f19a, 1},
+		{0x1f1e6, 0x1f1ff, 1},
+		{0x1f201, 0x1f21a, 25},
+		{0x1f22f, 0x1f22f, 1},
+		{0x1f232, 0x1f236, 1},
+		{0x1f238, 0x1f23a, 1},
+		{0x1f250, 0x1f251, 1},
+		{0x1f300, 0x1f320, 1},
+		{0x1f32d, 0x1f335, 1},
+		{0x1f337, 0x1f37c, 1},
+		{0x1f37e, 0x1f393, 1},
+		{0x1f3a0, 0x1f3ca, 1},
+		{0x1f3cf, 0x1f3d3, 1},
+		{0x1f3e0, 0x1f3f0, 1},
+		{0x1f3f4, 0x1f3f4, 1},
+		{0x1f3f8, 0x1f43e, 1},
+		{0x1f440, 0x1f440, 1},
+		{0x1f442, 0x1f4fc, 1},
+		{0x1f4ff, 0x1f53d, 1},
+		{0x1f54b, 0x1f54e, 1},
+		{0x1f550, 0x1f567, 1},
+		{0x1f57a, 0x1f57a, 1},
+		{0x1f595, 0x1f596, 1},
+		{0x1f5a4, 0x1f5a4, 1},
+		{0x1f5fb, 0x1f64f, 1},
+		{0x1f680, 0x1f6c5, 1},
+		{0x1f6cc, 0x1f6cc, 1},
+		{0x1f6d0, 0x1f6d2, 1},
+		{0x1f6d5, 0x1f6d7, 1},
+		{0x1f6dc, 0x1f6df, 1},
+		{0x1f6eb, 0x1f6ec, 1},
+		{0x1f6f4, 0x1f6fc, 1},
+		{0x1f7e0, 0x1f7eb, 1},
+		{0x1f7f0, 0x1f7f0, 1},
+		{0x1f90c, 0x1f93a, 1},
+		{0x1f93c, 0x1f945, 1},
+		{0x1f947, 0x1f9ff, 1},
+		{0x1fa70, 0x1fa7c, 1},
+		{0x1fa80, 0x1fa88, 1},
+		{0x1fa90, 0x1fabd, 1},
+		{0x1fabf, 0x1fac5, 1},
+		{0x1face, 0x1fadb, 1},
+		{0x1fae0, 0x1fae8, 1},
+		{0x1faf0, 0x1faf8, 1},
+	},
+}
+
+// emojiText contains the characters with the Emoji property but without
+// Emoji_Presentation, excluding the ASCII keycap bases. These default to text
+// presentation and only form an emoji when followed by a variation selector or
+// a skin tone modifier, or when joined to another emoji.
+var emojiText = &unicode.RangeTable{
+	LatinOffset: 1,
+	R16: []unicode.Range16{
+		{0x00a9, 0x00ae, 5},
+		{0x203c, 0x2049, 13},
+		{0x2122, 0x2139, 23},
+		{0x2194, 0x2199, 1},
+		{0x21a9, 0x21aa, 1},
+		{0x2328, 0x23cf, 167},
+		{0x23ed, 0x23ef, 1},
+		{0x23f1, 0x23f2, 1},
+		{0x23f8, 0x23fa, 1},
+		{0x24c2, 0x24c2, 1},
+		{0x25aa, 0x25ab, 1},
+		{0x25b6, 0x25c0, 10},
+		{0x25fb, 0x25fc, 1},
+		{0x2600, 0x2604, 1},
+		{0x260e, 0x2611, 3},
+		{0x2618, 0x261d, 5},
+		{0x2620, 0x2620, 1},
+		{0x2622, 0x2623, 1},
+		{0x2626, 0x262a, 4},
+		{0x262e, 0x262f, 1},
+		{0x2638, 0x263a, 1},
+		{0x2640, 0x2642, 2},
+		{0x265f, 0x2660, 1},
+		{0x2663, 0x2663, 1},
+		{0x2665, 0x2666, 1},
+		{0x2668, 0x2668, 1},
+		{0x267b, 0x267e, 3},
+		{0x2692, 0x2692, 1},
+		{0x2694, 0x2697, 1},
+		{0x2699, 0x2699, 1},
+		{0x269b, 0x269c, 1},
+		{0x26a0, 0x26a0, 1},
+		{0x26a7, 0x26a7, 1},
+		{0x26b0, 0x26b1, 1},
+		{0x26c8, 0x26c8, 1},
+		{0x26cf, 0x26cf, 1},
+		{0x26d1, 0x26d3, 2},
+		{0x26e9, 0x26e9, 1},
+		{0x26f0, 0x26f1, 1},
+		{0x26f4, 0x26f4, 1},
+		{0x26f7, 0x26f9, 1},
+		{0x2702, 0x2702, 1},
+		{0x2708, 0x2709, 1},
+		{0x270c, 0x270d, 1},
+		{0x270f, 0x270f, 1},
+		{0x2712, 0x2716, 2},
+		{0x271d, 0x2721, 4},
+		{0x2733, 0x2734, 1},
+		{0x2744, 0x2747, 3},
+		{0x2763, 0x2764, 1},
+		{0x27a1, 0x27a1, 1},
+		{0x2934, 0x2935, 1},
+		{0x2b05, 0x2b07, 1},
+		{0x3030, 0x303d, 13},
+		{0x3297, 0x3299, 2},
+	},
+	R32: []unicode.Range32{
+		{0x1f170, 0x1f171, 1},
+		{0x1f17e, 0x1f17f, 1},
+		{0x1f202, 0x1f202, 1},
+		{0x1f237, 0x1f237, 1},
+		{0x1f321, 0x1f321, 1},
+		{0x1f324, 0x1f32c, 1},
+		{0x1f336, 0x1f37d, 71},
+		{0x1f396, 0x1f397, 1},
+		{0x1f399, 0x1f39b, 1},
+		{0x1f39e, 0x1f39f, 1},
+		{0x1f3cb, 0x1f3ce, 1},
+		{0x1f3d4, 0x1f3df, 1},
+		{0x1f3f3, 0x1f3f5, 2},
+		{0x1f3f7, 0x1f43f, 72},
+		{0x1f441, 0x1f4fd, 188},
+		{0x1f549, 0x1f54a, 1},
+		{0x1f56f, 0x1f570, 1},
+		{0x1f573, 0x1f579, 1},
+		{0x1f587, 0x1f587, 1},
+		{0x1f58a, 0x1f58d, 1},
+		{0x1f590, 0x1f5a5, 21},
+		{0x1f5a8, 0x1f5a8, 1},
+		{0x1f5b1, 0x1f5b2, 1},
+		{0x1f5bc, 0x1f5bc, 1},
+		{0x1f5c2, 0x1f5c4, 1},
+		{0x1f5d1, 0x1f5d3, 1},
+		{0x1f5dc, 0x1f5de, 1},
+		{0x1f5e1, 0x1f5e3, 2},
+		{0x1f5e8, 0x1f5ef, 7},
+		{0x1f5f3, 0x1f5fa, 7},
+		{0x1f6cb, 0x1f6cd, 2},
+		{0x1f6ce, 0x1f6cf, 1},
+		{0x1f6e0, 0x1f6e5, 1},
+		{0x1f6e9, 0x1f6f0, 7},
+		{0x1f6f3, 0x1f6f3, 1},
+	},
+}
+
+// emojiModifier contains the Fitzpatrick skin tone modifiers.
+var emojiModifier = &unicode.RangeTable{
+	R32: []unicode.Range32{
+		{0x1f3fb, 0x1f3ff, 1},
+	},
+}
+
+// emojiTag contains the tag characters used by emoji tag sequences such as
+// subdivision flags, excluding the CANCEL TAG terminator.
+var emojiTag = &unicode.RangeTable{
+	R32: []unicode.Range32{
+		{0xe0020, 0xe007e, 1},
+	},
+}
+
+// isEmojiCandidate is a cheap check for runes that cannot start an emoji,
+// which includes every ASCII rune other than the keycap bases. The lexer uses
+// it to skip emojiLen for most runes.
+func isEmojiCandidate(r rune) bool {
+	if r < 0x80 {
+		return isKeycapBase(r)
+	}
+	return r >= 0xa9
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= regionalIndicatorA && r <= regionalIndicatorZ
+}
+
+func isKeycapBase(r rune) bool {
+	return r == '#' || r == '*' || (r >= '0' && r <= '9')
+}
+
+func runeAt(input []rune, i int) rune {
+	if i < len(input) {
+		return input[i]
+	}
+	return eof
+}
+
+// emojiLen returns the length in runes of the emoji sequence starting at
+// input[i] or 0 if none starts there. Recognized sequences follow the
+// possible emoji grammar from UTR #51: keycaps, regional indicator pairs,
+// presentation and modifier sequences, tag sequences and zwj sequences of any
+// of these.
+func emojiLen(input []rune, i int) int {
+	r := runeAt(input, i)
+	if !isEmojiCandidate(r) {
+		return 0
+	}
+
+	if isKeycapBase(r) {
+		j := i + 1
+		if runeAt(input, j) == variationSelector {
+			j++
+		}
+		if runeAt(input, j) == combiningKeycap {
+			return j + 1 - i
+		}
+		return 0
+	}
+
+	if isRegionalIndicator(r) {
+		if isRegionalIndicator(runeAt(input, i+1)) {
+			return 2
+		}
+		return 1
+	}
+
+	j, ok := emojiElementEnd(input, i, false)
+	if !ok {
+		return 0
+	}
+	for runeAt(input, j) == zeroWidthJoiner {
+		k, ok := emojiElementEnd(input, j+1, true)
+		if !ok {
+			break
+		}
+		j = k
+	}
+	return j - i
+}
+
+// emojiElementEnd returns the end of the emoji element starting at input[i].
+// Text presentation characters are only accepted when they are qualified by a
+// variation selector, a modifier or a tag sequence, or when joined is set.
+func emojiElementEnd(input []rune, i int, joined bool) (int, bool) {
+	r := runeAt(input, i)
+	presentation := unicode.Is(emojiPresentation, r)
+	if !presentation && !unicode.Is(emojiText, r) {
+		return i, false
+	}
+
+	j := i + 1
+	if runeAt(input, j) == variationSelector {
+		presentation = true
+		j++
+	}
+	if unicode.Is(emojiModifier, runeAt(input, j)) {
+		presentation = true
+		j++
+	}
+	if unicode.Is(emojiTag, runeAt(input, j)) {
+		k := j + 1
+		for unicode.Is(emojiTag, runeAt(input, k)) {
+			k++
+		}
+		if runeAt(input, k) == tagCancel {
+			presentation = true
+			j = k + 1
+		}
+	}
+
+	return j, presentation || joined
+}
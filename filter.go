@@ -0,0 +1,212 @@
+package parser
+
+import (
+	"sync"
+	"unicode"
+)
+
+// FilterAction is the moderation action taken for a matched phrase. Actions
+// are ordered by severity.
+type FilterAction int
+
+const (
+	FilterFlag FilterAction = iota
+	FilterMask
+	FilterReject
+)
+
+var filterActionNames = map[FilterAction]string{
+	FilterFlag:   "Flag",
+	FilterMask:   "Mask",
+	FilterReject: "Reject",
+}
+
+func (a FilterAction) String() string {
+	return filterActionNames[a]
+}
+
+// FilterRule is a banned phrase. Phrases are case insensitive and match whole
+// words; separators between words in the phrase match any run of spaces and
+// punctuation. A * matches any number of word characters, so "spam*" matches
+// "spammer" and "*" on its own matches any one word.
+type FilterRule struct {
+	Phrase string
+	Action FilterAction
+}
+
+// FilterMatch is the range of the input matched by a rule.
+type FilterMatch struct {
+	Phrase string
+	Action FilterAction
+	TokPos int
+	TokEnd int
+}
+
+type filterRule struct {
+	FilterRule
+	words [][]rune
+}
+
+func compileFilterRule(r FilterRule) (c filterRule) {
+	c.FilterRule = r
+
+	phrase := runeSliceToLower([]rune(r.Phrase), nil)
+	for i := 0; i < len(phrase); i++ {
+		j := i
+		for j < len(phrase) && (phrase[j] == '*' || isWordRune(phrase[j])) {
+			j++
+		}
+		if j > i {
+			c.words = append(c.words, phrase[i:j])
+		}
+		i = j
+	}
+	return
+}
+
+func NewFilter(rules []FilterRule) *Filter {
+	f := &Filter{}
+	f.Replace(rules)
+	return f
+}
+
+// Filter matches banned phrases against the visible text of parsed messages.
+// Span markup is removed before matching so phrases split by spoilers or
+// hidden in code are found, while nicks, links and channel references are
+// never matched.
+type Filter struct {
+	sync.Mutex
+	rules []filterRule
+}
+
+// Insert adds a rule. Rules without any words are ignored as in Replace.
+func (f *Filter) Insert(r FilterRule) {
+	c := compileFilterRule(r)
+	if len(c.words) == 0 {
+		return
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	f.rules = append(f.rules, c)
+}
+
+func (f *Filter) Replace(rules []FilterRule) {
+	c := make([]filterRule, 0, len(rules))
+	for _, r := range rules {
+		if cr := compileFilterRule(r); len(cr.words) != 0 {
+			c = append(c, cr)
+		}
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	f.rules = c
+}
+
+func filterTextMode(n Node) textMode {
+	switch n.(type) {
+	case *Nick, *Link, *ChannelRef:
+		return textBreak
+	case *Emoji:
+		return textSeparate
+	}
+	return textInclude
+}
+
+// Match returns the ranges of input matched by the filter's rules in order
+// of position.
+func (f *Filter) Match(input string, msg *Span) (matches []FilterMatch) {
+	words := newTextView([]rune(input), msg, filterTextMode).words()
+
+	f.Lock()
+	defer f.Unlock()
+
+	for i := range words {
+		for _, r := range f.rules {
+			if n := r.matchAt(words, i); n != 0 {
+				matches = append(matches, FilterMatch{
+					Phrase: r.Phrase,
+					Action: r.Action,
+					TokPos: words[i].pos,
+					TokEnd: words[i+n-1].end,
+				})
+			}
+		}
+	}
+	return
+}
+
+// matchAt returns the number of words matched starting at words[i].
+func (r *filterRule) matchAt(words []textWord, i int) int {
+	if i+len(r.words) > len(words) {
+		return 0
+	}
+	for j, p := range r.words {
+		w := words[i+j]
+		if j != 0 && !w.joined {
+			return 0
+		}
+		if !matchWildcard(p, w.text) {
+			return 0
+		}
+	}
+	return len(r.words)
+}
+
+// matchWildcard reports whether s matches pattern p where * matches any
+// sequence of runes.
+func matchWildcard(p, s []rune) bool {
+	var pi, si int
+	star, mark := -1, 0
+	for si < len(s) {
+		switch {
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, si
+			pi++
+		case pi < len(p) && p[pi] == s[si]:
+			pi++
+			si++
+		case star != -1:
+			pi = star + 1
+			mark++
+			si = mark
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// MatchAction returns the most severe action of matches.
+func MatchAction(matches []FilterMatch) (a FilterAction, ok bool) {
+	for _, m := range matches {
+		if !ok || m.Action > a {
+			a, ok = m.Action, true
+		}
+	}
+	return
+}
+
+// MaskMatches replaces the word characters covered by masking matches with
+// mask. Markup and punctuation inside the matched ranges are kept so the
+// result parses like the original.
+func MaskMatches(input string, matches []FilterMatch, mask rune) string {
+	runes := []rune(input)
+	for _, m := range matches {
+		if m.Action != FilterMask {
+			continue
+		}
+		for i := m.TokPos; i < m.TokEnd && i < len(runes); i++ {
+			if !unicode.Is(nonWord, runes[i]) {
+				runes[i] = mask
+			}
+		}
+	}
+	return string(runes)
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	ctx := NewParserContext(ParserContextValues{
+		Emotes: []string{"PEPE"},
+		Nicks:  []string{"spammer"},
+	})
+	f := NewFilter([]FilterRule{
+		{Phrase: "bad word", Action: FilterMask},
+		{Phrase: "spam*", Action: FilterReject},
+		{Phrase: "b*d", Action: FilterFlag},
+	})
+
+	cases := []struct {
+		name     string
+		input    string
+		expected []FilterMatch
+	}{
+		{"phrase", "this is a bad word", []FilterMatch{
+			{"bad word", FilterMask, 10, 18},
+			{"b*d", FilterFlag, 10, 13},
+		}},
+		{"split by spoiler", "ba||d wo||rd", []FilterMatch{
+			{"bad word", FilterMask, 0, 12},
+			{"b*d", FilterFlag, 0, 5},
+		}},
+		{"inside code", "`bread  Word`", []FilterMatch{
+			{"b*d", FilterFlag, 1, 6},
+		}},
+		{"word boundary", "notbad words", nil},
+		{"wildcard", "stop spamming", []FilterMatch{
+			{"spam*", FilterReject, 5, 13},
+		}},
+		{"nick", "hi spammer", nil},
+		{"across nick", "bad spammer word", []FilterMatch{
+			{"b*d", FilterFlag, 0, 3},
+		}},
+	}
+
+	for _, c := range cases {
+		p := NewParser(ctx, NewLexer(c.input))
+		matches := f.Match(c.input, p.ParseMessage())
+
+		if !reflect.DeepEqual(c.expected, matches) {
+			t.Errorf("%s: got %v expected %v", c.name, matches, c.expected)
+		}
+	}
+}
+
+func TestFilterEmptyRules(t *testing.T) {
+	ctx := newTestParserContext()
+	f := NewFilter([]FilterRule{{Phrase: "", Action: FilterReject}})
+	f.Insert(FilterRule{Phrase: "", Action: FilterReject})
+	f.Insert(FilterRule{Phrase: " ,. ", Action: FilterReject})
+
+	if len(f.rules) != 0 {
+		t.Errorf("expected empty rules to be ignored, got %d rules", len(f.rules))
+	}
+	input := "hello there"
+	if matches := f.Match(input, NewParser(ctx, NewLexer(input)).ParseMessage()); len(matches) != 0 {
+		t.Errorf("expected empty rules not to match, got %v", matches)
+	}
+}
+
+func TestMaskMatches(t *testing.T) {
+	f := NewFilter([]FilterRule{
+		{Phrase: "bad word", Action: FilterMask},
+	})
+
+	input := "ba||d wo||rd!"
+	p := NewParser(NewParserContext(ParserContextValues{}), NewLexer(input))
+	matches := f.Match(input, p.ParseMessage())
+
+	if a, ok := MatchAction(matches); !ok || a != FilterMask {
+		t.Errorf("expected mask action got %s", a)
+	}
+
+	expected := "**||* **||**!"
+	if masked := MaskMatches(input, matches, '*'); masked != expected {
+		t.Errorf("got %q expected %q", masked, expected)
+	}
+}
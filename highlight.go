@@ -0,0 +1,149 @@
+package parser
+
+type HighlightClass int
+
+const (
+	HighlightText HighlightClass = iota
+	HighlightEmote
+	HighlightNick
+	HighlightTag
+	HighlightLink
+	HighlightEmoji
+	HighlightChannel
+	HighlightSpoiler
+	HighlightSpoilerMarker
+	HighlightCode
+	HighlightCodeMarker
+	HighlightGreentext
+	HighlightGreentextMarker
+	HighlightCommand
+	HighlightError
+)
+
+var highlightClassNames = map[HighlightClass]string{
+	HighlightText:            "Text",
+	HighlightEmote:           "Emote",
+	HighlightNick:            "Nick",
+	HighlightTag:             "Tag",
+	HighlightLink:            "Link",
+	HighlightEmoji:           "Emoji",
+	HighlightChannel:         "Channel",
+	HighlightSpoiler:         "Spoiler",
+	HighlightSpoilerMarker:   "SpoilerMarker",
+	HighlightCode:            "Code",
+	HighlightCodeMarker:      "CodeMarker",
+	HighlightGreentext:       "Greentext",
+	HighlightGreentextMarker: "GreentextMarker",
+	HighlightCommand:         "Command",
+	HighlightError:           "Error",
+}
+
+func (c HighlightClass) String() string {
+	return highlightClassNames[c]
+}
+
+// HighlightRange classifies the runes at input[TokPos:TokEnd].
+type HighlightRange struct {
+	Class  HighlightClass
+	TokPos int
+	TokEnd int
+}
+
+// Highlight classifies every rune of input using its parsed message msg and
+// returns the classes as ordered, non-overlapping ranges covering the whole
+// input. Nested nodes take precedence over the spans containing them. Span
+// delimiters get marker classes of their own, and the opening markers of
+// unclosed spoilers and code spans as well as rejected emote modifiers are
+// classified as errors.
+func Highlight(input string, msg *Span) []HighlightRange {
+	runes := []rune(input)
+	classes := make([]HighlightClass, len(runes))
+
+	paint := func(pos, end int, c HighlightClass) {
+		for i := pos; i < end && i < len(classes); i++ {
+			if i >= 0 {
+				classes[i] = c
+			}
+		}
+	}
+
+	paint(0, commandLen(runes, msg), HighlightCommand)
+
+	Inspect(msg, func(n Node) bool {
+		switch n := n.(type) {
+		case *Span:
+			highlightSpan(runes, n, paint)
+			return true
+		case *Emote:
+			paint(n.TokPos, n.TokEnd, HighlightEmote)
+			for _, r := range n.Rejected {
+				paint(r.TokPos, r.TokEnd, HighlightError)
+			}
+		case *Nick:
+			paint(n.TokPos, n.TokEnd, HighlightNick)
+		case *Tag:
+			paint(n.TokPos, n.TokEnd, HighlightTag)
+		case *Link:
+			paint(n.TokPos, n.TokEnd, HighlightLink)
+		case *Emoji:
+			paint(n.TokPos, n.TokEnd, HighlightEmoji)
+		case *ChannelRef:
+			paint(n.TokPos, n.TokEnd, HighlightChannel)
+		}
+		return false
+	})
+
+	var ranges []HighlightRange
+	for i, c := range classes {
+		if n := len(ranges); n != 0 && ranges[n-1].Class == c {
+			ranges[n-1].TokEnd = i + 1
+		} else {
+			ranges = append(ranges, HighlightRange{
+				Class:  c,
+				TokPos: i,
+				TokEnd: i + 1,
+			})
+		}
+	}
+	return ranges
+}
+
+func highlightSpan(input []rune, s *Span, paint func(pos, end int, c HighlightClass)) {
+	var body, marker HighlightClass
+	switch s.Type {
+	case SpanGreentext:
+		body, marker = HighlightGreentext, HighlightGreentextMarker
+	case SpanSpoiler:
+		body, marker = HighlightSpoiler, HighlightSpoilerMarker
+	case SpanCode:
+		body, marker = HighlightCode, HighlightCodeMarker
+	default:
+		return
+	}
+
+	open, close := spanMarkers(input, s)
+	paint(s.TokPos, s.TokEnd, body)
+	if close == s.TokEnd && s.Type != SpanGreentext {
+		paint(s.TokPos, open, HighlightError)
+	} else {
+		paint(s.TokPos, open, marker)
+		paint(close, s.TokEnd, marker)
+	}
+}
+
+// commandLen returns the length of the command at the start of input. The
+// message is not a command if it starts with anything but a slash followed by
+// a word.
+func commandLen(input []rune, msg *Span) int {
+	if len(input) < 2 || input[0] != '/' || msg.Type == SpanGreentext {
+		return 0
+	}
+	n := 1
+	for n < len(input) && isWordRune(input[n]) {
+		n++
+	}
+	if n == 1 {
+		return 0
+	}
+	return n
+}
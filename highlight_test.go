@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	ctx := newTestParserContext()
+
+	cases := []struct {
+		name     string
+		input    string
+		expected []HighlightRange
+	}{
+		{"empty", "", nil},
+		{"nodes", "PEPE hi @abeous", []HighlightRange{
+			{HighlightEmote, 0, 4},
+			{HighlightText, 4, 8},
+			{HighlightNick, 8, 15},
+		}},
+		{"spoiler", "||PEPE|| x", []HighlightRange{
+			{HighlightSpoilerMarker, 0, 2},
+			{HighlightEmote, 2, 6},
+			{HighlightSpoilerMarker, 6, 8},
+			{HighlightText, 8, 10},
+		}},
+		{"spoiler text", "||a b||", []HighlightRange{
+			{HighlightSpoilerMarker, 0, 2},
+			{HighlightSpoiler, 2, 5},
+			{HighlightSpoilerMarker, 5, 7},
+		}},
+		{"unclosed code", "`code", []HighlightRange{
+			{HighlightError, 0, 1},
+			{HighlightCode, 1, 5},
+		}},
+		{"empty code", "``", []HighlightRange{
+			{HighlightCodeMarker, 0, 2},
+		}},
+		{"greentext", "> hi nsfw", []HighlightRange{
+			{HighlightGreentextMarker, 0, 1},
+			{HighlightGreentext, 1, 5},
+			{HighlightTag, 5, 9},
+		}},
+		{"me", "/me waves", []HighlightRange{
+			{HighlightCommand, 0, 3},
+			{HighlightText, 3, 9},
+		}},
+		{"command", "/w wrxst hi", []HighlightRange{
+			{HighlightCommand, 0, 2},
+			{HighlightText, 2, 3},
+			{HighlightNick, 3, 8},
+			{HighlightText, 8, 11},
+		}},
+		{"link and channel", "www.x.com #strims 😀", []HighlightRange{
+			{HighlightLink, 0, 9},
+			{HighlightText, 9, 10},
+			{HighlightChannel, 10, 17},
+			{HighlightText, 17, 18},
+			{HighlightEmoji, 18, 19},
+		}},
+	}
+
+	for _, c := range cases {
+		p := NewParser(ctx, NewLexer(c.input))
+		ranges := Highlight(c.input, p.ParseMessage())
+
+		if !reflect.DeepEqual(c.expected, ranges) {
+			t.Errorf("%s: got %v expected %v", c.name, ranges, c.expected)
+		}
+	}
+}
+
+func TestHighlightRejectedModifier(t *testing.T) {
+	ctx := NewParserContext(ParserContextValues{
+		Emotes:         []string{"PEPE"},
+		EmoteModifiers: []string{"wide", "hue"},
+		EmoteModifierRules: []EmoteModifierRule{
+			{Name: "hue", MinArgs: 1, MaxArgs: 1},
+		},
+	})
+
+	input := "PEPE:hue:wide"
+	ranges := Highlight(input, NewParser(ctx, NewLexer(input)).ParseMessage())
+	expected := []HighlightRange{
+		{HighlightEmote, 0, 5},
+		{HighlightError, 5, 8},
+		{HighlightEmote, 8, 13},
+	}
+	if !reflect.DeepEqual(expected, ranges) {
+		t.Errorf("got %v expected %v", ranges, expected)
+	}
+}
+
+func TestHighlightCoversInput(t *testing.T) {
+	ctx := newTestParserContext()
+	for _, input := range readCorpus(884) {
+		ranges := Highlight(input, NewParser(ctx, NewLexer(input)).ParseMessage())
+
+		var end int
+		for _, r := range ranges {
+			if r.TokPos != end || r.TokEnd <= r.TokPos {
+				t.Fatalf("%q: ranges are not contiguous: %v", input, ranges)
+			}
+			end = r.TokEnd
+		}
+		if n := len([]rune(input)); end != n {
+			t.Fatalf("%q: ranges end at %d, expected %d", input, end, n)
+		}
+	}
+}
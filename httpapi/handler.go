@@ -0,0 +1,267 @@
+// Package httpapi serves the parser over HTTP with JSON requests and
+// responses so services written in other languages get the same results.
+//
+// Parse endpoints take {"input": "...", "offsets": "runes"} where offsets is
+// one of "runes" (the default), "utf16" or "bytes" and selects the unit of
+// positions in the response.
+//
+//	POST /parse                   the message AST
+//	POST /render                  {"html": "..."}
+//	POST /tokens                  {"ranges": [{"class", "pos", "end"}]}
+//	POST /context/update          applies a list of parser.ContextUpdate
+//	POST /context/nicks/add       {"values": [...]}
+//	POST /context/nicks/remove    {"values": [...]}
+//	POST /context/emotes/replace  {"values": [...]}
+//
+// Errors are returned as {"error": "..."}.
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	parser "github.com/MemeLabs/chat-parser"
+)
+
+// DefaultMaxBodyBytes is the request size limit used when none is set.
+const DefaultMaxBodyBytes = 64 << 10
+
+var errBodyTooLarge = errors.New("request body too large")
+
+func NewHandler(ctx *parser.ParserContext) *Handler {
+	h := &Handler{
+		ctx:          ctx,
+		mux:          http.NewServeMux(),
+		MaxBodyBytes: DefaultMaxBodyBytes,
+	}
+	h.mux.HandleFunc("/parse", h.post(h.parse))
+	h.mux.HandleFunc("/render", h.post(h.render))
+	h.mux.HandleFunc("/tokens", h.post(h.tokens))
+	h.mux.HandleFunc("/context/update", h.post(h.update))
+	h.mux.HandleFunc("/context/nicks/add", h.post(h.addNicks))
+	h.mux.HandleFunc("/context/nicks/remove", h.post(h.removeNicks))
+	h.mux.HandleFunc("/context/emotes/replace", h.post(h.replaceEmotes))
+	return h
+}
+
+// Handler serves parse requests against a shared parser context. Context
+// updates apply to all later requests.
+type Handler struct {
+	ctx *parser.ParserContext
+	mux *http.ServeMux
+	// MaxBodyBytes limits the size of request bodies.
+	MaxBodyBytes int64
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type httpError struct {
+	status int
+	err    error
+}
+
+// post wraps an endpoint that decodes the request body into a value and
+// returns the response to encode.
+func (h *Handler) post(f func(r *http.Request) (interface{}, *httpError)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{"method not allowed"})
+			return
+		}
+		r.Body = &limitedBody{r.Body, h.MaxBodyBytes}
+
+		res, err := f(r)
+		if err != nil {
+			writeJSON(w, err.status, errorResponse{err.err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+	}
+}
+
+// limitedBody returns errBodyTooLarge once more than n bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.n < 0 {
+		return 0, errBodyTooLarge
+	}
+	if int64(len(p)) > b.n+1 {
+		p = p[:b.n+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) <= b.n {
+		b.n -= int64(n)
+		return n, err
+	}
+	n = int(b.n)
+	b.n = -1
+	return n, errBodyTooLarge
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+func decode(r *http.Request, v interface{}) *httpError {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		if errors.Is(err, errBodyTooLarge) {
+			return &httpError{http.StatusRequestEntityTooLarge, errBodyTooLarge}
+		}
+		return &httpError{http.StatusBadRequest, err}
+	}
+	return nil
+}
+
+type parseRequest struct {
+	Input   string `json:"input"`
+	Offsets string `json:"offsets"`
+}
+
+// parseInput decodes a parse request and parses its input. The returned
+// function converts rune offsets to the requested unit.
+func (h *Handler) parseInput(r *http.Request) (string, *parser.Span, func(int) int, *httpError) {
+	var req parseRequest
+	if err := decode(r, &req); err != nil {
+		return "", nil, nil, err
+	}
+
+	p := parser.NewParser(h.ctx, parser.NewLexer(req.Input))
+	msg := p.ParseMessage()
+
+	var offset func(int) int
+	switch req.Offsets {
+	case "", "runes":
+		offset = func(pos int) int { return pos }
+	case "utf16":
+		offset = parser.UTF16Offsets(req.Input)
+	case "bytes":
+		offset = p.ByteOffset
+	default:
+		return "", nil, nil, &httpError{http.StatusBadRequest, errors.New("unknown offsets unit " + req.Offsets)}
+	}
+	return req.Input, msg, offset, nil
+}
+
+func (h *Handler) parse(r *http.Request) (interface{}, *httpError) {
+	_, msg, offset, err := h.parseInput(r)
+	if err != nil {
+		return nil, err
+	}
+	b, merr := parser.MarshalNodeJSON(msg, offset)
+	if merr != nil {
+		return nil, &httpError{http.StatusInternalServerError, merr}
+	}
+	return json.RawMessage(b), nil
+}
+
+type renderResponse struct {
+	HTML string `json:"html"`
+}
+
+func (h *Handler) render(r *http.Request) (interface{}, *httpError) {
+	input, msg, _, err := h.parseInput(r)
+	if err != nil {
+		return nil, err
+	}
+	return renderResponse{parser.RenderHTML(input, msg)}, nil
+}
+
+type tokenRange struct {
+	Class string `json:"class"`
+	Pos   int    `json:"pos"`
+	End   int    `json:"end"`
+}
+
+type tokensResponse struct {
+	Ranges []tokenRange `json:"ranges"`
+}
+
+func (h *Handler) tokens(r *http.Request) (interface{}, *httpError) {
+	input, msg, offset, err := h.parseInput(r)
+	if err != nil {
+		return nil, err
+	}
+
+	res := tokensResponse{Ranges: []tokenRange{}}
+	for _, hr := range parser.Highlight(input, msg) {
+		res.Ranges = append(res.Ranges, tokenRange{
+			Class: hr.Class.String(),
+			Pos:   offset(hr.TokPos),
+			End:   offset(hr.TokEnd),
+		})
+	}
+	return res, nil
+}
+
+type okResponse struct {
+	OK bool `json:"ok"`
+}
+
+func (h *Handler) update(r *http.Request) (interface{}, *httpError) {
+	var req []parser.ContextUpdate
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return h.apply(req...)
+}
+
+// apply sends updates through ParserContext.Update so contexts missing an
+// index report an error instead of panicking.
+func (h *Handler) apply(updates ...parser.ContextUpdate) (interface{}, *httpError) {
+	for _, u := range updates {
+		if err := h.ctx.Update(u); err != nil {
+			return nil, &httpError{http.StatusBadRequest, err}
+		}
+	}
+	return okResponse{true}, nil
+}
+
+type valuesRequest struct {
+	Values []string `json:"values"`
+}
+
+// updateValues applies op to each of the requested values in index.
+func (h *Handler) updateValues(r *http.Request, op, index string) (interface{}, *httpError) {
+	var req valuesRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	updates := make([]parser.ContextUpdate, len(req.Values))
+	for i, v := range req.Values {
+		updates[i] = parser.ContextUpdate{Op: op, Index: index, Value: v}
+	}
+	return h.apply(updates...)
+}
+
+func (h *Handler) addNicks(r *http.Request) (interface{}, *httpError) {
+	return h.updateValues(r, "insert", "nicks")
+}
+
+func (h *Handler) removeNicks(r *http.Request) (interface{}, *httpError) {
+	return h.updateValues(r, "remove", "nicks")
+}
+
+func (h *Handler) replaceEmotes(r *http.Request) (interface{}, *httpError) {
+	var req valuesRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return h.apply(parser.ContextUpdate{Op: "replace", Index: "emotes", Values: req.Values})
+}
@@ -0,0 +1,121 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	parser "github.com/MemeLabs/chat-parser"
+)
+
+func newTestHandler() *Handler {
+	return NewHandler(parser.NewParserContext(parser.ParserContextValues{
+		Emotes: []string{"PEPE"},
+		Nicks:  []string{"abeous"},
+		Tags:   []string{"nsfw"},
+	}))
+}
+
+func do(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	cases := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		res    string
+	}{
+		{
+			"parse", "/parse", `{"input":"PEPE abeous"}`, http.StatusOK,
+			`{"type":"Span","spanType":"Message","nodes":[{"type":"Emote","name":"PEPE","modifiers":[],"pos":0,"end":4},{"type":"Nick","nick":"abeous","pos":5,"end":11}],"pos":0,"end":11}`,
+		},
+		{
+			"utf16 offsets", "/parse", `{"input":"😀 PEPE","offsets":"utf16"}`, http.StatusOK,
+			`{"type":"Span","spanType":"Message","nodes":[{"type":"Emoji","text":"😀","pos":0,"end":2},{"type":"Emote","name":"PEPE","modifiers":[],"pos":3,"end":7}],"pos":0,"end":7}`,
+		},
+		{
+			"byte offsets", "/tokens", `{"input":"ü PEPE","offsets":"bytes"}`, http.StatusOK,
+			`{"ranges":[{"class":"Text","pos":0,"end":3},{"class":"Emote","pos":3,"end":7}]}`,
+		},
+		{
+			"render", "/render", `{"input":"nsfw <3"}`, http.StatusOK,
+			`{"html":"<span class=\"chat-tag chat-tag-nsfw\">nsfw</span> &lt;3"}`,
+		},
+		{
+			"tokens", "/tokens", `{"input":"||PEPE||"}`, http.StatusOK,
+			`{"ranges":[{"class":"SpoilerMarker","pos":0,"end":2},{"class":"Emote","pos":2,"end":6},{"class":"SpoilerMarker","pos":6,"end":8}]}`,
+		},
+		{
+			"empty tokens", "/tokens", `{"input":""}`, http.StatusOK,
+			`{"ranges":[]}`,
+		},
+		{"bad json", "/parse", `{"input":`, http.StatusBadRequest, ""},
+		{"bad offsets", "/parse", `{"input":"a","offsets":"lines"}`, http.StatusBadRequest, ""},
+		{"at limit", "/render", `{"input":"` + strings.Repeat("a", DefaultMaxBodyBytes-12) + `"}`, http.StatusOK, ""},
+		{"too large", "/parse", `{"input":"` + strings.Repeat("a", DefaultMaxBodyBytes) + `"}`, http.StatusRequestEntityTooLarge, `{"error":"request body too large"}`},
+		{"bad update", "/context/update", `[{"op":"insert","index":"foo","value":"x"}]`, http.StatusBadRequest, ""},
+		{"not found", "/foo", `{}`, http.StatusNotFound, ""},
+	}
+
+	h := newTestHandler()
+	for _, c := range cases {
+		w := do(h, http.MethodPost, c.path, c.body)
+		if w.Code != c.status {
+			t.Errorf("%s: got status %d expected %d: %s", c.name, w.Code, c.status, w.Body)
+		}
+		if res := strings.TrimSpace(w.Body.String()); c.res != "" && res != c.res {
+			t.Errorf("%s: got\n%s\nexpected\n%s", c.name, res, c.res)
+		}
+	}
+}
+
+func TestHandlerMethod(t *testing.T) {
+	w := do(newTestHandler(), http.MethodGet, "/parse", "")
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("expected method not allowed, got %d", w.Code)
+	}
+}
+
+func TestHandlerContext(t *testing.T) {
+	h := newTestHandler()
+
+	steps := []struct {
+		path string
+		body string
+	}{
+		{"/context/nicks/add", `{"values":["wrxst"]}`},
+		{"/context/nicks/remove", `{"values":["abeous"]}`},
+		{"/context/emotes/replace", `{"values":["CuckCrab"]}`},
+		{"/context/update", `[{"op":"insert","index":"tags","value":"nsfl"}]`},
+	}
+	for _, s := range steps {
+		if w := do(h, http.MethodPost, s.path, s.body); w.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d: %s", s.path, w.Code, w.Body)
+		}
+	}
+
+	w := do(h, http.MethodPost, "/tokens", `{"input":"PEPE CuckCrab abeous wrxst nsfl"}`)
+	expected := `{"ranges":[{"class":"Text","pos":0,"end":5},{"class":"Emote","pos":5,"end":13},{"class":"Text","pos":13,"end":21},{"class":"Nick","pos":21,"end":26},{"class":"Text","pos":26,"end":27},{"class":"Tag","pos":27,"end":31}]}`
+	if res := strings.TrimSpace(w.Body.String()); res != expected {
+		t.Errorf("got\n%s\nexpected\n%s", res, expected)
+	}
+}
+
+func TestHandlerMissingIndex(t *testing.T) {
+	h := NewHandler(&parser.ParserContext{})
+
+	for _, path := range []string{"/context/nicks/add", "/context/nicks/remove", "/context/emotes/replace"} {
+		if w := do(h, http.MethodPost, path, `{"values":["PEPE"]}`); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d expected %d: %s", path, w.Code, http.StatusBadRequest, w.Body)
+		}
+	}
+	if w := do(h, http.MethodPost, "/context/update", `[{"op":"insert","index":"tags","value":"nsfl"}]`); w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d expected %d: %s", w.Code, http.StatusBadRequest, w.Body)
+	}
+}
@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidEdit = errors.New("edit out of range")
+
+// parseCheckpoint is the parser state at a whitespace token between top level
+// nodes of a message. Tokens before a whitespace token are lexed and parsed
+// without looking past it, so parsing can resume there after any edit that
+// starts beyond it.
+type parseCheckpoint struct {
+	pos    int
+	tokens int
+	nodes  int
+	tags   int
+	// children is the number of nodes in the message before pos.
+	children int
+}
+
+func (p *Parser) checkpoint(s *Span) {
+	p.checkpoints = append(p.checkpoints, parseCheckpoint{
+		pos:      p.pos,
+		tokens:   p.tokens,
+		nodes:    p.nodes,
+		tags:     len(p.tags),
+		children: len(s.Nodes),
+	})
+}
+
+// Edit replaces the runes at input[Pos:End] with Text.
+type Edit struct {
+	Pos  int
+	End  int
+	Text string
+}
+
+// NewIncrementalParser parses input and returns a parser that keeps the
+// resulting AST up to date as the input is edited.
+func NewIncrementalParser(ctx *ParserContext, input string) *IncrementalParser {
+	p := NewParser(ctx, lexer{})
+	p.checkpoints = make([]parseCheckpoint, 0, 16)
+
+	ip := &IncrementalParser{
+		p:     p,
+		input: []rune(input),
+	}
+	ip.parse()
+	return ip
+}
+
+// IncrementalParser reparses a message after each edit, starting from the
+// last point before the edit where parsing can resume and reusing the top
+// level nodes before it. Only that prefix is reused; everything after the
+// checkpoint is parsed again, so edits near the start of a long message cost
+// as much as a full parse. Reused nodes are shared with earlier ASTs and must
+// not be modified.
+type IncrementalParser struct {
+	p     *Parser
+	input []rune
+	msg   *Span
+	buf   []rune
+}
+
+// Input returns the current input.
+func (ip *IncrementalParser) Input() string {
+	return string(ip.input)
+}
+
+// Message returns the AST of the current input.
+func (ip *IncrementalParser) Message() *Span {
+	return ip.msg
+}
+
+// Apply edits the input and returns the updated AST. Edit positions are rune
+// offsets into the current input. Edits outside of the input are rejected with
+// ErrInvalidEdit and leave the parser unchanged.
+func (ip *IncrementalParser) Apply(e Edit) (*Span, error) {
+	if e.Pos < 0 || e.End < e.Pos || e.End > len(ip.input) {
+		return nil, fmt.Errorf("%w: [%d:%d] of %d runes", ErrInvalidEdit, e.Pos, e.End, len(ip.input))
+	}
+
+	ip.buf = append(ip.buf[:0], ip.input[:e.Pos]...)
+	for _, r := range e.Text {
+		ip.buf = append(ip.buf, r)
+	}
+	ip.buf = append(ip.buf, ip.input[e.End:]...)
+	ip.input, ip.buf = ip.buf, ip.input
+
+	cps := ip.p.checkpoints
+	i := len(cps) - 1
+	for i >= 0 && cps[i].pos >= e.Pos {
+		i--
+	}
+	if i < 0 || ip.msg.Limited != 0 {
+		ip.parse()
+	} else {
+		ip.resume(i)
+	}
+	return ip.msg, nil
+}
+
+func (ip *IncrementalParser) parse() {
+	p := ip.p
+	p.lexer.resetRunes(ip.input)
+	p.reset()
+	p.checkpoints = p.checkpoints[:0]
+	ip.msg = p.ParseMessage()
+}
+
+// resume reparses the message from the i-th checkpoint. The parser still
+// holds the tags and checkpoints of the previous parse, which are valid up
+// to the checkpoint.
+func (ip *IncrementalParser) resume(i int) {
+	p := ip.p
+	cp := p.checkpoints[i]
+	tags := p.tags[:cp.tags]
+
+	p.lexer.resetRunes(ip.input)
+	p.reset()
+	p.lexer.start = cp.pos
+	p.lexer.pos = cp.pos - 1
+	p.tokens = cp.tokens - 1
+	p.nodes = cp.nodes
+	p.tags = tags
+	p.checkpoints = p.checkpoints[:i]
+
+	s := &Span{
+		Type:   ip.msg.Type,
+		Nodes:  append([]Node(nil), ip.msg.Nodes[:cp.children]...),
+		TokPos: ip.msg.TokPos,
+	}
+	p.next()
+	p.parseSpanBody(s, SpanMessage)
+	s.Limited = p.limited
+	ip.msg = s
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+var incrementalFragments = []string{
+	"PEPE", "CuckCrab", ":wide", ":spin", " ", "  ", "\n", "a", "x",
+	"||", "`", "\\", "@abeous", "wrxst", "nsfw", "nsfl", ":thumbsup:", ":",
+	"#strims", "#destiny", "> ", "/me ", "/", "https://example.com/a.png",
+	"www.test.com", ")", "(", "😀", "🏳️‍🌈", "1️⃣", "‍", "ü",
+}
+
+func randomEdit(r *rand.Rand, input []rune) Edit {
+	pos := r.Intn(len(input) + 1)
+	end := pos
+	if r.Intn(3) == 0 {
+		end += r.Intn(len(input) - pos + 1)
+	}
+
+	var text string
+	if r.Intn(4) != 0 {
+		for n := r.Intn(3) + 1; n > 0; n-- {
+			text += incrementalFragments[r.Intn(len(incrementalFragments))]
+		}
+	}
+	return Edit{Pos: pos, End: end, Text: text}
+}
+
+func TestIncrementalParser(t *testing.T) {
+	ctx := newTestParserContext()
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 200; i++ {
+		ip := NewIncrementalParser(ctx, "")
+		for j := 0; j < 50; j++ {
+			prev := ip.Input()
+			e := randomEdit(r, []rune(prev))
+			ast, err := ip.Apply(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := NewParser(ctx, NewLexer(ip.Input())).ParseMessage()
+			if !reflect.DeepEqual(expected, ast) {
+				t.Fatalf("%q after %+v: got\n%s\nexpected\n%s", prev, e, spew.Sdump(ast), spew.Sdump(expected))
+			}
+		}
+	}
+}
+
+func TestIncrementalParserLimits(t *testing.T) {
+	ctx := newTestParserContext()
+	ctx.Limits = ParseLimits{MaxRunes: 60, MaxNodes: 6, MaxTokens: 40}
+	r := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 100; i++ {
+		ip := NewIncrementalParser(ctx, "PEPE wrxst ")
+		for j := 0; j < 30; j++ {
+			prev := ip.Input()
+			e := randomEdit(r, []rune(prev))
+			ast, err := ip.Apply(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := NewParser(ctx, NewLexer(ip.Input())).ParseMessage()
+			if !reflect.DeepEqual(expected, ast) {
+				t.Fatalf("%q after %+v: got\n%s\nexpected\n%s", prev, e, spew.Sdump(ast), spew.Sdump(expected))
+			}
+		}
+	}
+}
+
+func TestIncrementalParserReuse(t *testing.T) {
+	ip := NewIncrementalParser(newTestParserContext(), "PEPE abeous ")
+	prev := ip.Message()
+
+	ast, err := ip.Apply(Edit{Pos: 12, End: 12, Text: "CuckCrab"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ast.Nodes) != 3 || ast.Nodes[0] != prev.Nodes[0] || ast.Nodes[1] != prev.Nodes[1] {
+		t.Errorf("expected nodes before the edit to be reused, got\n%s", spew.Sdump(ast))
+	}
+	if len(prev.Nodes) != 2 {
+		t.Errorf("expected previous AST to be unchanged, got\n%s", spew.Sdump(prev))
+	}
+}
+
+func TestIncrementalParserInvalidEdit(t *testing.T) {
+	ip := NewIncrementalParser(newTestParserContext(), "PEPE abeous")
+	prev := ip.Message()
+
+	for _, e := range []Edit{
+		{Pos: -1, End: 0},
+		{Pos: 3, End: 2},
+		{Pos: 11, End: 12},
+		{Pos: 12, End: 12, Text: "x"},
+	} {
+		if ast, err := ip.Apply(e); !errors.Is(err, ErrInvalidEdit) || ast != nil {
+			t.Errorf("%+v: expected ErrInvalidEdit, got %v", e, err)
+		}
+	}
+	if ip.Input() != "PEPE abeous" || ip.Message() != prev {
+		t.Errorf("expected parser to be unchanged, got %q", ip.Input())
+	}
+}
@@ -0,0 +1,164 @@
+package parser
+
+import "encoding/json"
+
+// MarshalNodeJSON encodes an AST as JSON. Every node is an object with a
+// "type" field naming its Go type. Positions are rune offsets unless offset
+// is set, in which case it converts them.
+func MarshalNodeJSON(n Node, offset func(pos int) int) ([]byte, error) {
+	if offset == nil {
+		offset = func(pos int) int { return pos }
+	}
+	return json.Marshal(jsonNode(n, offset))
+}
+
+// UTF16Offsets returns a function converting rune offsets into input to
+// offsets in UTF-16 code units, the unit of JavaScript string indices.
+func UTF16Offsets(input string) func(pos int) int {
+	var offsets []int
+	var n int
+	for i, r := range []rune(input) {
+		if r > 0xffff && offsets == nil {
+			offsets = make([]int, i, len(input)+1)
+			for j := range offsets {
+				offsets[j] = j
+			}
+		}
+		if offsets != nil {
+			offsets = append(offsets, n)
+		}
+		if r > 0xffff {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	if offsets == nil {
+		return func(pos int) int { return pos }
+	}
+	offsets = append(offsets, n)
+	return func(pos int) int {
+		if pos < 0 || pos >= len(offsets) {
+			return pos
+		}
+		return offsets[pos]
+	}
+}
+
+type jsonSpan struct {
+	Type     string        `json:"type"`
+	SpanType string        `json:"spanType"`
+	Nodes    []interface{} `json:"nodes"`
+	Pos      int           `json:"pos"`
+	End      int           `json:"end"`
+	Limited  string        `json:"limited,omitempty"`
+}
+
+type jsonEmote struct {
+	Type         string                 `json:"type"`
+	Name         string                 `json:"name"`
+	Modifiers    []string               `json:"modifiers"`
+	ModifierArgs [][]string             `json:"modifierArgs,omitempty"`
+	Rejected     []jsonRejectedModifier `json:"rejected,omitempty"`
+	Pos          int                    `json:"pos"`
+	End          int                    `json:"end"`
+}
+
+type jsonRejectedModifier struct {
+	Name   string   `json:"name"`
+	Args   []string `json:"args,omitempty"`
+	Reason string   `json:"reason"`
+	Pos    int      `json:"pos"`
+	End    int      `json:"end"`
+}
+
+type jsonEmoji struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+	Pos  int    `json:"pos"`
+	End  int    `json:"end"`
+}
+
+type jsonTag struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Pos  int    `json:"pos"`
+	End  int    `json:"end"`
+}
+
+type jsonNick struct {
+	Type string      `json:"type"`
+	Nick string      `json:"nick"`
+	Meta interface{} `json:"meta,omitempty"`
+	Pos  int         `json:"pos"`
+	End  int         `json:"end"`
+}
+
+type jsonLink struct {
+	Type  string   `json:"type"`
+	URL   string   `json:"url"`
+	Class string   `json:"class"`
+	Tags  []string `json:"tags,omitempty"`
+	Pos   int      `json:"pos"`
+	End   int      `json:"end"`
+}
+
+type jsonChannelRef struct {
+	Type        string `json:"type"`
+	Channel     string `json:"channel"`
+	DisplayName string `json:"displayName"`
+	Live        bool   `json:"live"`
+	Pos         int    `json:"pos"`
+	End         int    `json:"end"`
+}
+
+func jsonNode(n Node, offset func(int) int) interface{} {
+	switch n := n.(type) {
+	case *Span:
+		s := &jsonSpan{
+			Type:     "Span",
+			SpanType: n.Type.String(),
+			Nodes:    make([]interface{}, len(n.Nodes)),
+			Pos:      offset(n.TokPos),
+			End:      offset(n.TokEnd),
+			Limited:  n.Limited.String(),
+		}
+		for i, c := range n.Nodes {
+			s.Nodes[i] = jsonNode(c, offset)
+		}
+		return s
+	case *Emote:
+		e := &jsonEmote{
+			Type:         "Emote",
+			Name:         n.Name,
+			Modifiers:    n.Modifiers,
+			ModifierArgs: n.ModifierArgs,
+			Pos:          offset(n.TokPos),
+			End:          offset(n.TokEnd),
+		}
+		if e.Modifiers == nil {
+			e.Modifiers = []string{}
+		}
+		for _, r := range n.Rejected {
+			e.Rejected = append(e.Rejected, jsonRejectedModifier{
+				Name:   r.Name,
+				Args:   r.Args,
+				Reason: r.Reason.String(),
+				Pos:    offset(r.TokPos),
+				End:    offset(r.TokEnd),
+			})
+		}
+		return e
+	case *Emoji:
+		return &jsonEmoji{"Emoji", string(n.Codepoints), offset(n.TokPos), offset(n.TokEnd)}
+	case *Tag:
+		return &jsonTag{"Tag", n.Name, offset(n.TokPos), offset(n.TokEnd)}
+	case *Nick:
+		return &jsonNick{"Nick", n.Nick, n.Meta, offset(n.TokPos), offset(n.TokEnd)}
+	case *Link:
+		return &jsonLink{"Link", n.URL, n.Class.String(), n.Tags, offset(n.TokPos), offset(n.TokEnd)}
+	case *ChannelRef:
+		return &jsonChannelRef{"ChannelRef", n.Channel, n.DisplayName, n.Live, offset(n.TokPos), offset(n.TokEnd)}
+	}
+	return nil
+}
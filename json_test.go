@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var updateFixtures = flag.Bool("update", false, "rewrite testdata fixtures")
+
+// jsonFixtures are the parse tests encoded as JSON ASTs with UTF-16 offsets.
+// They are shared with the wasm harness, which replays Context and Updates to
+// rebuild the test parser context.
+type jsonFixtures struct {
+	Context ParserContextValues
+	Updates []ContextUpdate
+	Tests   []jsonFixture
+}
+
+type jsonFixture struct {
+	Name  string          `json:"name"`
+	Input string          `json:"input"`
+	AST   json.RawMessage `json:"ast"`
+}
+
+var jsonFixturesPath = filepath.Join("testdata", "wasm", "fixtures.json")
+
+func newJSONFixtures() jsonFixtures {
+	return jsonFixtures{
+		Context: ParserContextValues{
+			Emotes:         []string{"PEPE", "CuckCrab"},
+			EmoteModifiers: []string{"wide", "rustle", "spin"},
+			Nicks:          []string{"abeous", "jeanpierrepratt", "wrxst"},
+			Tags:           []string{"nsfw", "nsfl"},
+			Shortcodes: map[string]string{
+				"thumbsup": "👍",
+				"+1":       "👍",
+			},
+			Channels: []string{"strims"},
+		},
+		Updates: []ContextUpdate{
+			{Op: "insert", Index: "shortcodes", Value: "pepe", Replacement: "PEPE"},
+			{Op: "insert", Index: "channels", Value: "destiny", DisplayName: "Destiny", Live: true},
+			{Op: "insert", Index: "emotes", Value: "OMEGALUL"},
+			{Op: "remove", Index: "emotes", Value: "OMEGALUL"},
+		},
+	}
+}
+
+func TestJSONFixtures(t *testing.T) {
+	f := newJSONFixtures()
+	ctx := NewParserContext(f.Context)
+	for _, u := range f.Updates {
+		if err := ctx.Update(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectedCtx := newTestParserContext()
+
+	for _, test := range parseTests {
+		input := test.input
+		ast, err := MarshalNodeJSON(NewParser(ctx, NewLexer(input)).ParseMessage(), UTF16Offsets(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := MarshalNodeJSON(NewParser(expectedCtx, NewLexer(input)).ParseMessage(), UTF16Offsets(input))
+		if !bytes.Equal(expected, ast) {
+			t.Errorf("%s: fixture context parses differently from the test context:\n%s\n%s", test.name, ast, expected)
+		}
+		f.Tests = append(f.Tests, jsonFixture{test.name, input, ast})
+	}
+
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b = append(b, '\n')
+
+	if *updateFixtures {
+		if err := ioutil.WriteFile(jsonFixturesPath, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d, err := ioutil.ReadFile(jsonFixturesPath); err != nil || !bytes.Equal(b, d) {
+		t.Errorf("%s is out of date, run go test -run TestJSONFixtures -update", jsonFixturesPath)
+	}
+}
+
+func TestContextUpdateMissingIndex(t *testing.T) {
+	ctx := &ParserContext{}
+	for _, index := range []string{"emotes", "emoteModifiers", "nicks", "tags", "shortcodes", "channels", "commands"} {
+		for _, op := range []string{"insert", "remove"} {
+			if err := ctx.Update(ContextUpdate{Op: op, Index: index, Value: "PEPE"}); !errors.Is(err, ErrMissingIndex) {
+				t.Errorf("%s %s: expected ErrMissingIndex, got %v", op, index, err)
+			}
+		}
+	}
+	if err := ctx.Update(ContextUpdate{Op: "insert", Index: "foo"}); !errors.Is(err, ErrUnknownIndex) {
+		t.Errorf("expected ErrUnknownIndex, got %v", err)
+	}
+}
+
+func TestContextUpdateReplace(t *testing.T) {
+	ctx := newTestParserContext()
+	ctx.Nicks.InsertWithMeta([]rune("bob"), "mod")
+
+	if err := ctx.Update(ContextUpdate{Op: "replace", Index: "emotes", Values: []string{"OMEGALUL"}}); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Emotes.Contains([]rune("PEPE")) || !ctx.Emotes.Contains([]rune("OMEGALUL")) {
+		t.Error("expected emotes to be replaced")
+	}
+	if err := ctx.Update(ContextUpdate{Op: "replace", Index: "nicks", Values: []string{"Bob"}}); err != nil {
+		t.Fatal(err)
+	}
+	if it := ctx.Nicks.Get([]rune("bob")); it == nil || it.meta != "mod" || ctx.Nicks.Contains([]rune("abeous")) {
+		t.Errorf("expected nicks to be replaced, got %+v", it)
+	}
+	if err := ctx.Update(ContextUpdate{Op: "replace", Index: "channels"}); !errors.Is(err, ErrUnknownUpdateOp) {
+		t.Errorf("expected ErrUnknownUpdateOp, got %v", err)
+	}
+}
+
+func TestUTF16Offsets(t *testing.T) {
+	offset := UTF16Offsets("a😀b€c")
+	for pos, expected := range []int{0, 1, 3, 4, 5, 6} {
+		if o := offset(pos); o != expected {
+			t.Errorf("rune offset %d: got %d expected %d", pos, o, expected)
+		}
+	}
+
+	if o := UTF16Offsets("ab€")(3); o != 3 {
+		t.Errorf("expected BMP offsets to be unchanged, got %d", o)
+	}
+}
+
+func TestMarshalNodeJSON(t *testing.T) {
+	input := "😀 PEPE:wide"
+	b, err := MarshalNodeJSON(NewParser(newTestParserContext(), NewLexer(input)).ParseMessage(), UTF16Offsets(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"type":"Span","spanType":"Message","nodes":[` +
+		`{"type":"Emoji","text":"😀","pos":0,"end":2},` +
+		`{"type":"Emote","name":"PEPE","modifiers":["wide"],"pos":3,"end":12}` +
+		`],"pos":0,"end":12}`
+	if string(b) != expected {
+		t.Errorf("got %s expected %s", b, expected)
+	}
+}
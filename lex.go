@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/text/unicode/rangetable"
 )
@@ -23,6 +24,9 @@ const (
 	tokAt
 	tokRSlash
 	tokEscapeSeq
+	tokEmoji
+	tokHash
+	tokLink
 )
 
 var tokNames = map[tokType]string{
@@ -37,6 +41,9 @@ var tokNames = map[tokType]string{
 	tokAt:         "At",
 	tokRSlash:     "RSlash",
 	tokEscapeSeq:  "EscapeSeq",
+	tokEmoji:      "Emoji",
+	tokHash:       "Hash",
+	tokLink:       "Link",
 }
 
 func (i tokType) String() string {
@@ -54,17 +61,186 @@ func (i token) String() string {
 }
 
 func NewLexer(input string) lexer {
-	return lexer{
-		input: []rune(input),
-		pos:   -1,
-	}
+	var l lexer
+	l.reset(input, 0)
+	return l
+}
+
+// NewLexerBytes returns a lexer for UTF-8 encoded input. It produces the same
+// tokens as NewLexer(string(input)) without copying input to a string first,
+// but still decodes it to runes.
+func NewLexerBytes(input []byte) lexer {
+	var l lexer
+	l.resetBytes(input, 0)
+	return l
 }
 
 type lexer struct {
-	input      []rune
+	input []rune
+	// offsets holds the byte offset of every rune in input followed by the
+	// offset of the end of input and, if decoding stopped early, the length
+	// of the source. It is empty when the source is ASCII and rune and byte
+	// offsets coincide.
+	offsets []int
+	// size is the length of the source in runes. It exceeds len(input) when
+	// decoding stopped at a rune limit.
+	size       int
 	start, pos int
 }
 
+// reset prepares the lexer for new input, reusing its rune buffer. If max
+// is not zero decoding stops after max+1 runes, which is enough for the
+// parser to detect that a rune limit of max was exceeded.
+func (l *lexer) reset(input string, max int) {
+	l.decode(input, nil, max)
+}
+
+// resetBytes is reset for UTF-8 encoded input. Invalid bytes decode to
+// utf8.RuneError one byte at a time as they do when converting a string.
+func (l *lexer) resetBytes(input []byte, max int) {
+	l.decode("", input, max)
+}
+
+// decode fills the rune buffer from the UTF-8 source s, or b when it is not
+// nil, and records byte offsets once a multibyte rune is seen. Sources whose
+// rune count equals their length hold no multibyte runes, only ASCII and
+// invalid bytes, so they are copied without decoding.
+func (l *lexer) decode(s string, b []byte, max int) {
+	n := len(s)
+	if b != nil {
+		n, l.size = len(b), utf8.RuneCount(b)
+	} else {
+		l.size = utf8.RuneCountInString(s)
+	}
+	runes := l.size
+	if max > 0 && runes > max+1 {
+		runes = max + 1
+	}
+	if cap(l.input) < runes {
+		l.input = make([]rune, 0, runes)
+	}
+
+	l.offsets = l.offsets[:0]
+	l.start = 0
+	l.pos = -1
+
+	if l.size == n {
+		l.input = l.input[:runes]
+		for i := range l.input {
+			var c byte
+			if b != nil {
+				c = b[i]
+			} else {
+				c = s[i]
+			}
+			if c < utf8.RuneSelf {
+				l.input[i] = rune(c)
+			} else {
+				l.input[i] = utf8.RuneError
+			}
+		}
+		return
+	}
+
+	l.input = l.input[:0]
+	ascii := true
+	i := 0
+	for i < n && len(l.input) < runes {
+		var r rune
+		if b != nil {
+			r = rune(b[i])
+		} else {
+			r = rune(s[i])
+		}
+		w := 1
+		if r >= utf8.RuneSelf {
+			if b != nil {
+				r, w = utf8.DecodeRune(b[i:])
+			} else {
+				r, w = utf8.DecodeRuneInString(s[i:])
+			}
+			if ascii {
+				l.offsets = appendASCIIOffsets(l.offsets, i)
+				ascii = false
+			}
+		}
+		if !ascii {
+			l.offsets = append(l.offsets, i)
+		}
+		l.input = append(l.input, r)
+		i += w
+	}
+	if i < n {
+		if ascii && l.size != n {
+			l.offsets = appendASCIIOffsets(l.offsets, len(l.input))
+			ascii = false
+		}
+		if !ascii {
+			l.offsets = append(l.offsets, i)
+		}
+	}
+	if !ascii {
+		l.offsets = append(l.offsets, n)
+	}
+}
+
+// truncate drops the input past its first max+1 runes like decode does when
+// given a rune limit.
+func (l *lexer) truncate(max int) {
+	if max <= 0 || len(l.input) <= max+1 {
+		return
+	}
+	if len(l.offsets) != 0 {
+		n := l.offsets[len(l.offsets)-1]
+		l.offsets = append(l.offsets[:max+2], n)
+	}
+	l.input = l.input[:max+1]
+}
+
+// resetRunes prepares the lexer for input that is already decoded. Byte
+// offsets are not available for it.
+func (l *lexer) resetRunes(input []rune) {
+	l.input = input
+	l.offsets = l.offsets[:0]
+	l.size = len(input)
+	l.start = 0
+	l.pos = -1
+}
+
+// appendASCIIOffsets appends the offsets of the n ASCII runes preceding the
+// first multibyte rune, which equal their index.
+func appendASCIIOffsets(dst []int, n int) []int {
+	for i := 0; i < n; i++ {
+		dst = append(dst, i)
+	}
+	return dst
+}
+
+// byteOffset converts the rune offset pos into a byte offset in the source.
+// Past the decoded input only the end of the source, l.size, is valid.
+func (l *lexer) byteOffset(pos int) int {
+	if len(l.offsets) == 0 {
+		return pos
+	}
+	if pos > len(l.input) {
+		return l.offsets[len(l.offsets)-1]
+	}
+	return l.offsets[pos]
+}
+
+func appendRuneBytes(dst []byte, v []rune) []byte {
+	var b [utf8.UTFMax]byte
+	for _, r := range v {
+		if r < utf8.RuneSelf {
+			dst = append(dst, byte(r))
+		} else {
+			n := utf8.EncodeRune(b[:], r)
+			dst = append(dst, b[:n]...)
+		}
+	}
+	return dst
+}
+
 func (l *lexer) next() rune {
 	l.pos++
 	if l.pos < len(l.input) {
@@ -107,7 +283,40 @@ var nonWord = rangetable.Merge(
 	unicode.White_Space,
 )
 
+// asciiNonWord caches the nonWord lookup for ASCII runes.
+var asciiNonWord = func() (t [utf8.RuneSelf]bool) {
+	for r := range t {
+		t[r] = unicode.Is(nonWord, rune(r))
+	}
+	return
+}()
+
+func isNonWord(r rune) bool {
+	if r >= 0 && r < utf8.RuneSelf {
+		return asciiNonWord[r]
+	}
+	return unicode.Is(nonWord, r)
+}
+
+func (l *lexer) acceptEmoji() bool {
+	if !isEmojiCandidate(runeAt(l.input, l.pos+1)) {
+		return false
+	}
+	n := emojiLen(l.input, l.pos+1)
+	l.pos += n
+	return n != 0
+}
+
+func (l *lexer) isWordRune(i int) bool {
+	r := runeAt(l.input, i)
+	return r != eof && !isNonWord(r) && (!isEmojiCandidate(r) || emojiLen(l.input, i) == 0)
+}
+
 func (l *lexer) Next() token {
+	if l.acceptEmoji() {
+		return l.emit(tokEmoji)
+	}
+
 	r := l.next()
 	switch r {
 	case eof:
@@ -121,6 +330,8 @@ func (l *lexer) Next() token {
 		return l.emit(tokRAngle)
 	case '@':
 		return l.emit(tokAt)
+	case '#':
+		return l.emit(tokHash)
 	case '/':
 		return l.emit(tokRSlash)
 	case '\\':
@@ -140,10 +351,14 @@ func (l *lexer) Next() token {
 			for l.accept(func(r rune) bool { return unicode.IsSpace(r) }) {
 			}
 			return l.emit(tokWhitespace)
-		} else if unicode.Is(nonWord, r) {
+		} else if isNonWord(r) {
 			return l.emit(tokPunct)
+		} else if n := linkLen(l.input, l.pos); n != 0 {
+			l.pos += n - 1
+			return l.emit(tokLink)
 		} else {
-			for l.accept(func(r rune) bool { return r != eof && !unicode.Is(nonWord, r) }) {
+			for l.isWordRune(l.pos + 1) {
+				l.pos++
 			}
 			return l.emit(tokWord)
 		}
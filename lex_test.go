@@ -3,6 +3,7 @@ package parser
 import (
 	"log"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -51,9 +52,45 @@ var lexTests = []lexTest{
 		mkItem(tokEOF, 22, ""),
 	}},
 	{"emoji", "🙈🙉🙊", []token{
-		mkItem(tokWord, 0, "🙈🙉🙊"),
+		mkItem(tokEmoji, 0, "🙈"),
+		mkItem(tokEmoji, 1, "🙉"),
+		mkItem(tokEmoji, 2, "🙊"),
 		mkItem(tokEOF, 3, ""),
 	}},
+	{"emoji zwj sequence", "👩\u200d👩\u200d👧", []token{
+		mkItem(tokEmoji, 0, "👩\u200d👩\u200d👧"),
+		mkItem(tokEOF, 5, ""),
+	}},
+	{"emoji skin tone", "👍🏽👍", []token{
+		mkItem(tokEmoji, 0, "👍🏽"),
+		mkItem(tokEmoji, 2, "👍"),
+		mkItem(tokEOF, 3, ""),
+	}},
+	{"emoji flags", "🇺🇸🏴\U000e0067\U000e0062\U000e0073\U000e0063\U000e0074\U000e007f", []token{
+		mkItem(tokEmoji, 0, "🇺🇸"),
+		mkItem(tokEmoji, 2, "🏴\U000e0067\U000e0062\U000e0073\U000e0063\U000e0074\U000e007f"),
+		mkItem(tokEOF, 9, ""),
+	}},
+	{"emoji keycap", "1\ufe0f\u20e3 #\u20e3 #", []token{
+		mkItem(tokEmoji, 0, "1\ufe0f\u20e3"),
+		mkItem(tokWhitespace, 3, " "),
+		mkItem(tokEmoji, 4, "#\u20e3"),
+		mkItem(tokWhitespace, 6, " "),
+		mkItem(tokHash, 7, "#"),
+		mkItem(tokEOF, 8, ""),
+	}},
+	{"emoji text presentation", "\u2764 \u2764\ufe0f", []token{
+		mkItem(tokPunct, 0, "\u2764"),
+		mkItem(tokWhitespace, 1, " "),
+		mkItem(tokEmoji, 2, "\u2764\ufe0f"),
+		mkItem(tokEOF, 4, ""),
+	}},
+	{"emoji adjacent to word", "PEPE🙈PEPE", []token{
+		mkItem(tokWord, 0, "PEPE"),
+		mkItem(tokEmoji, 4, "🙈"),
+		mkItem(tokWord, 5, "PEPE"),
+		mkItem(tokEOF, 9, ""),
+	}},
 	{"non ascii words", "日本語のテキスト", []token{
 		mkItem(tokWord, 0, "日本語のテキスト"),
 		mkItem(tokEOF, 8, ""),
@@ -68,6 +105,53 @@ var lexTests = []lexTest{
 		mkItem(tokWord, 11, "𒈙𒐫﷽"),
 		mkItem(tokEOF, 14, ""),
 	}},
+	{"channel", "#strims", []token{
+		mkItem(tokHash, 0, "#"),
+		mkItem(tokWord, 1, "strims"),
+		mkItem(tokEOF, 7, ""),
+	}},
+	{"links", "see https://strims.gg/angelthump. (www.example.com/a_(b)) ||http://x.y||", []token{
+		mkItem(tokWord, 0, "see"),
+		mkItem(tokWhitespace, 3, " "),
+		mkItem(tokLink, 4, "https://strims.gg/angelthump"),
+		mkItem(tokPunct, 32, "."),
+		mkItem(tokWhitespace, 33, " "),
+		mkItem(tokPunct, 34, "("),
+		mkItem(tokLink, 35, "www.example.com/a_(b)"),
+		mkItem(tokPunct, 56, ")"),
+		mkItem(tokWhitespace, 57, " "),
+		mkItem(tokSpoiler, 58, "||"),
+		mkItem(tokLink, 60, "http://x.y"),
+		mkItem(tokSpoiler, 70, "||"),
+		mkItem(tokEOF, 72, ""),
+	}},
+	{"link brackets", "(x.y www.a.com/(b)]]))", []token{
+		mkItem(tokPunct, 0, "("),
+		mkItem(tokWord, 1, "x"),
+		mkItem(tokPunct, 2, "."),
+		mkItem(tokWord, 3, "y"),
+		mkItem(tokWhitespace, 4, " "),
+		mkItem(tokLink, 5, "www.a.com/(b)"),
+		mkItem(tokPunct, 18, "]"),
+		mkItem(tokPunct, 19, "]"),
+		mkItem(tokPunct, 20, ")"),
+		mkItem(tokPunct, 21, ")"),
+		mkItem(tokEOF, 22, ""),
+	}},
+	{"not links", "http:// https:///foo", []token{
+		mkItem(tokWord, 0, "http"),
+		mkItem(tokColon, 4, ":"),
+		mkItem(tokRSlash, 5, "/"),
+		mkItem(tokRSlash, 6, "/"),
+		mkItem(tokWhitespace, 7, " "),
+		mkItem(tokWord, 8, "https"),
+		mkItem(tokColon, 13, ":"),
+		mkItem(tokRSlash, 14, "/"),
+		mkItem(tokRSlash, 15, "/"),
+		mkItem(tokRSlash, 16, "/"),
+		mkItem(tokWord, 17, "foo"),
+		mkItem(tokEOF, 20, ""),
+	}},
 	{"at", "@", []token{
 		mkItem(tokAt, 0, "@"),
 		mkItem(tokEOF, 1, ""),
@@ -88,8 +172,69 @@ func TestLex(t *testing.T) {
 	}
 }
 
+func TestLexLinkTrailingBrackets(t *testing.T) {
+	input := "www.a.com/" + strings.Repeat(")", 1<<17)
+	if n := linkLen([]rune(input), 0); n != 10 {
+		t.Errorf("got link length %d expected 10", n)
+	}
+}
+
+func TestLexBytes(t *testing.T) {
+	inputs := readCorpus(884)
+	for _, test := range lexTests {
+		inputs = append(inputs, test.input)
+	}
+	inputs = append(
+		inputs,
+		"\xff\xfe PEPE \xe2\x80",
+		"a\xffb",
+		"\x80",
+		"ü\xc0 PEPE",
+		"日本 @abeous",
+		"🏳️‍🌈 www.example.com/ü",
+	)
+
+	for _, input := range inputs {
+		// Invalid bytes must decode to utf8.RuneError as in a conversion.
+		runes := []rune(input)
+		var rl lexer
+		rl.resetRunes(runes)
+		expected := spew.Sdump(lexAll(&rl))
+
+		if sl := NewLexer(input); string(sl.input) != string(runes) {
+			t.Errorf("%q: NewLexer decoded %q", input, string(sl.input))
+		}
+		l := NewLexerBytes([]byte(input))
+		if string(l.input) != string(runes) {
+			t.Errorf("%q: NewLexerBytes decoded %q", input, string(l.input))
+		}
+		toks := lexAll(&l)
+
+		if got := spew.Sdump(toks); got != expected {
+			t.Errorf("%q: got\n%s\nexpected\n%s", input, got, expected)
+			continue
+		}
+
+		var b []byte
+		for _, tok := range toks {
+			pos, end := l.byteOffset(tok.pos), l.byteOffset(tok.pos+len(tok.val))
+			if pos != len(b) {
+				t.Errorf("%q: token %s starts at byte %d, expected %d", input, tok, pos, len(b))
+			}
+			b = append(b, input[pos:end]...)
+		}
+		if string(b) != input {
+			t.Errorf("%q: byte offsets reassembled %q", input, b)
+		}
+	}
+}
+
 func lex(input string) (tokens []token) {
 	l := NewLexer(input)
+	return lexAll(&l)
+}
+
+func lexAll(l *lexer) (tokens []token) {
 	for {
 		t := l.Next()
 		tokens = append(tokens, t)
@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var linkPrefixes = [][]rune{
+	[]rune("https://"),
+	[]rune("http://"),
+	[]rune("www."),
+}
+
+func hasPrefixFold(input []rune, i int, prefix []rune) bool {
+	if i+len(prefix) > len(input) {
+		return false
+	}
+	for j, r := range prefix {
+		if unicode.ToLower(input[i+j]) != r {
+			return false
+		}
+	}
+	return true
+}
+
+// linkLen returns the length of the link starting at input[i] or 0 if there
+// is none. Links start with a http or https scheme or with www. and end at
+// whitespace, a backtick or a spoiler marker. Trailing punctuation and
+// unbalanced closing brackets are not included.
+func linkLen(input []rune, i int) int {
+	var start int
+	for _, p := range linkPrefixes {
+		if hasPrefixFold(input, i, p) {
+			start = i + len(p)
+			break
+		}
+	}
+	if start == 0 {
+		return 0
+	}
+
+	end := start
+	for end < len(input) && !unicode.IsSpace(input[end]) && input[end] != '`' && !hasMarkerAt(input, end, spoilerMarker) {
+		end++
+	}
+
+	// balance holds the number of unclosed brackets of each kind in
+	// input[i:end]. It is negative when there are more closing brackets.
+	var balance [3]int
+	for _, r := range input[i:end] {
+		if k, d := bracket(r); d != 0 {
+			balance[k] += d
+		}
+	}
+
+	for end > start {
+		switch r := input[end-1]; r {
+		case '.', ',', ':', ';', '!', '?', '\'', '"', '*':
+			end--
+			continue
+		case ')', ']', '}':
+			if k, _ := bracket(r); balance[k] < 0 {
+				balance[k]++
+				end--
+				continue
+			}
+		}
+		break
+	}
+
+	if end == start || input[start] == '/' {
+		return 0
+	}
+	return end - i
+}
+
+// bracket returns the kind of bracket r is and 1 if it opens or -1 if it
+// closes. The delta is 0 for other runes.
+func bracket(r rune) (kind, delta int) {
+	switch r {
+	case '(':
+		return 0, 1
+	case ')':
+		return 0, -1
+	case '[':
+		return 1, 1
+	case ']':
+		return 1, -1
+	case '{':
+		return 2, 1
+	case '}':
+		return 2, -1
+	}
+	return 0, 0
+}
+
+type LinkClass int
+
+const (
+	LinkUnclassified LinkClass = iota
+	LinkAllowed
+	LinkWarned
+	LinkBlocked
+	LinkImage
+	LinkVideo
+	LinkInternal
+)
+
+var linkClassNames = map[LinkClass]string{
+	LinkUnclassified: "Unclassified",
+	LinkAllowed:      "Allowed",
+	LinkWarned:       "Warned",
+	LinkBlocked:      "Blocked",
+	LinkImage:        "Image",
+	LinkVideo:        "Video",
+	LinkInternal:     "Internal",
+}
+
+func (c LinkClass) String() string {
+	return linkClassNames[c]
+}
+
+// LinkPolicyValues lists the domains a link policy acts on. A domain matches
+// itself and all of its subdomains, so "example.com" covers
+// "cdn.example.com" but not "badexample.com". A wildcard like
+// "*.example.com" only matches subdomains.
+type LinkPolicyValues struct {
+	Internal []string
+	Allowed  []string
+	Warned   []string
+	Blocked  []string
+	// Video lists hosts whose links are videos regardless of their path.
+	Video []string
+	// Default is the class of links to domains not listed in Allowed. It is
+	// LinkAllowed when unset.
+	Default LinkClass
+}
+
+var (
+	imageExtensions = []string{".png", ".jpg", ".jpeg", ".gif", ".webp", ".avif"}
+	videoExtensions = []string{".mp4", ".webm", ".mov", ".gifv"}
+)
+
+func NewLinkPolicy(v LinkPolicyValues) *LinkPolicy {
+	p := &LinkPolicy{}
+	p.Replace(v)
+	return p
+}
+
+// LinkPolicy classifies links by domain and content type. Blocked and warned
+// domains take precedence over internal ones, which take precedence over
+// media detection.
+type LinkPolicy struct {
+	sync.Mutex
+	values LinkPolicyValues
+}
+
+func (p *LinkPolicy) Replace(v LinkPolicyValues) {
+	if v.Default == LinkUnclassified {
+		v.Default = LinkAllowed
+	}
+	for _, d := range []*[]string{&v.Internal, &v.Allowed, &v.Warned, &v.Blocked, &v.Video} {
+		*d = normalizeDomains(*d)
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.values = v
+}
+
+// normalizeDomains lower cases domains and trims their dots. Wildcards are
+// kept as the domain with a leading dot.
+func normalizeDomains(ds []string) []string {
+	n := make([]string, len(ds))
+	for i, d := range ds {
+		d = strings.ToLower(d)
+		if strings.HasPrefix(d, "*.") {
+			n[i] = "." + strings.Trim(d[2:], ".")
+		} else {
+			n[i] = strings.Trim(d, ".")
+		}
+	}
+	return n
+}
+
+// matchDomain reports whether host is one of domains or a subdomain of one.
+// Wildcard domains only match subdomains.
+func matchDomain(host string, domains []string) bool {
+	for _, d := range domains {
+		if strings.HasPrefix(d, ".") {
+			if strings.HasSuffix(host, d) {
+				return true
+			}
+		} else if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLinkURL parses a link recognized by linkLen, which may lack a scheme,
+// and returns it with its normalized host name.
+func parseLinkURL(link string) (*url.URL, string, bool) {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.Hostname() == "" {
+		return nil, "", false
+	}
+	return u, strings.TrimSuffix(strings.ToLower(u.Hostname()), "."), true
+}
+
+func (p *LinkPolicy) Classify(link string) LinkClass {
+	u, host, ok := parseLinkURL(link)
+	if !ok {
+		return LinkBlocked
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+
+	p.Lock()
+	defer p.Unlock()
+
+	switch {
+	case matchDomain(host, p.values.Blocked):
+		return LinkBlocked
+	case matchDomain(host, p.values.Warned):
+		return LinkWarned
+	case matchDomain(host, p.values.Internal):
+		return LinkInternal
+	case !matchDomain(host, p.values.Allowed) && p.values.Default != LinkAllowed:
+		return p.values.Default
+	case containsString(imageExtensions, ext):
+		return LinkImage
+	case containsString(videoExtensions, ext), matchDomain(host, p.values.Video):
+		return LinkVideo
+	}
+	return LinkAllowed
+}
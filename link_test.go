@@ -0,0 +1,43 @@
+package parser
+
+import "testing"
+
+func TestLinkPolicy(t *testing.T) {
+	p := NewLinkPolicy(LinkPolicyValues{
+		Internal: []string{"strims.gg"},
+		Allowed:  []string{"imgur.com", "youtube.com", "youtu.be"},
+		Warned:   []string{"i.imgur.com"},
+		Blocked:  []string{"*.evil.com", "bad.com"},
+		Video:    []string{"youtube.com", "youtu.be"},
+		Default:  LinkWarned,
+	})
+
+	cases := []struct {
+		url      string
+		expected LinkClass
+	}{
+		{"https://strims.gg/angelthump", LinkInternal},
+		{"https://chat.strims.gg", LinkInternal},
+		{"https://imgur.com/a.PNG", LinkImage},
+		{"https://i.imgur.com/a.png", LinkWarned},
+		{"https://www.youtube.com/watch?v=abc", LinkVideo},
+		{"http://evil.com/a.png", LinkWarned},
+		{"http://x.evil.com:8080/", LinkBlocked},
+		{"http://a.b.EVIL.com./", LinkBlocked},
+		{"http://bad.com/", LinkBlocked},
+		{"http://www.bad.com/", LinkBlocked},
+		{"https://notevil.com/", LinkWarned},
+		{"www.example.com/cat.gif", LinkWarned},
+	}
+
+	for _, c := range cases {
+		if class := p.Classify(c.url); class != c.expected {
+			t.Errorf("%s: got %s expected %s", c.url, class, c.expected)
+		}
+	}
+
+	p.Replace(LinkPolicyValues{})
+	if class := p.Classify("https://notevil.com/cat.gif"); class != LinkImage {
+		t.Errorf("expected default policy to allow images got %s", class)
+	}
+}
@@ -0,0 +1,156 @@
+package parser
+
+// MentionOccurrence is a single reference to a nick in a message.
+type MentionOccurrence struct {
+	// At is set for explicit @nick mentions.
+	At bool
+	// Spans lists the types of the spans enclosing the mention, outermost
+	// first. The message span itself is omitted.
+	Spans  []SpanType
+	TokPos int
+	TokEnd int
+}
+
+// In reports whether the occurrence is inside a span of type t.
+func (o *MentionOccurrence) In(t SpanType) bool {
+	for _, st := range o.Spans {
+		if st == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Mention is a nick referenced one or more times in a message.
+type Mention struct {
+	Nick        string
+	Meta        interface{}
+	Occurrences []MentionOccurrence
+}
+
+// Explicit reports whether any occurrence is an @nick mention.
+func (m *Mention) Explicit() bool {
+	for i := range m.Occurrences {
+		if m.Occurrences[i].At {
+			return true
+		}
+	}
+	return false
+}
+
+// Notify reports whether the nick is referenced outside of code.
+func (m *Mention) Notify() bool {
+	for i := range m.Occurrences {
+		if !m.Occurrences[i].In(SpanCode) {
+			return true
+		}
+	}
+	return false
+}
+
+// Preview reports whether the message can be shown in a notification, which
+// is when the nick is referenced outside of code and none of those references
+// are hidden in spoilers.
+func (m *Mention) Preview() bool {
+	notify := false
+	for i := range m.Occurrences {
+		o := &m.Occurrences[i]
+		if o.In(SpanCode) {
+			continue
+		}
+		if o.In(SpanSpoiler) {
+			return false
+		}
+		notify = true
+	}
+	return notify
+}
+
+// Mentions returns the nicks referenced in msg, which was parsed from input
+// with ctx, in order of their first occurrence. The parser does not look for
+// nicks in code spans so they are scanned separately.
+func Mentions(ctx *ParserContext, input string, msg *Span) (mentions []Mention) {
+	runes := []rune(input)
+	index := map[string]int{}
+	var spans []SpanType
+
+	add := func(n *Nick) {
+		o := MentionOccurrence{
+			At:     n.TokPos < len(runes) && runes[n.TokPos] == '@',
+			TokPos: n.TokPos,
+			TokEnd: n.TokEnd,
+		}
+		for _, t := range spans {
+			if t != SpanMessage {
+				o.Spans = append(o.Spans, t)
+			}
+		}
+
+		i, ok := index[n.Nick]
+		if !ok {
+			i = len(mentions)
+			index[n.Nick] = i
+			mentions = append(mentions, Mention{Nick: n.Nick, Meta: n.Meta})
+		}
+		mentions[i].Occurrences = append(mentions[i].Occurrences, o)
+	}
+
+	Inspect(msg, func(n Node) bool {
+		switch n := n.(type) {
+		case nil:
+			spans = spans[:len(spans)-1]
+		case *Span:
+			spans = append(spans, n.Type)
+			if n.Type == SpanCode {
+				open, close := spanMarkers(runes, n)
+				for _, cn := range codeNicks(ctx, runes, open, close) {
+					add(cn)
+				}
+			}
+			return true
+		case *Nick:
+			add(n)
+		}
+		return false
+	})
+	return
+}
+
+// codeNicks returns the nicks referenced in input[pos:end]. Words are matched
+// as in parseSpanBody so tags and emotes take precedence over nicks.
+func codeNicks(ctx *ParserContext, input []rune, pos, end int) (nicks []*Nick) {
+	if pos >= end || end > len(input) {
+		return
+	}
+
+	p := NewParser(ctx, lexer{})
+	p.lexer.resetRunes(input[pos:end])
+	p.next()
+
+	for p.tok != tokEOF {
+		switch p.tok {
+		case tokAt:
+			if n := p.tryParseAtNick(); n != nil {
+				nicks = append(nicks, n)
+			}
+		case tokWord:
+			if _, ok := ctx.Tags.Get(p.lit); ok {
+				p.next()
+			} else if _, ok := ctx.Emotes.Get(p.lit); ok {
+				p.next()
+			} else if it := ctx.Nicks.Get(p.lit); it != nil {
+				nicks = append(nicks, p.parseNick(it))
+			} else {
+				p.next()
+			}
+		default:
+			p.next()
+		}
+	}
+
+	for _, n := range nicks {
+		n.TokPos += pos
+		n.TokEnd += pos
+	}
+	return
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestMentions(t *testing.T) {
+	ctx := newTestParserContext()
+	ctx.Nicks.InsertWithMeta([]rune("Bob"), "mod")
+
+	cases := []struct {
+		name     string
+		input    string
+		expected []Mention
+	}{
+		{"none", "PEPE hi", nil},
+		{"bare", "hi abeous", []Mention{
+			{Nick: "abeous", Occurrences: []MentionOccurrence{{TokPos: 3, TokEnd: 9}}},
+		}},
+		{"dedup", "@ABEOUS abeous", []Mention{
+			{Nick: "abeous", Occurrences: []MentionOccurrence{
+				{At: true, TokPos: 0, TokEnd: 7},
+				{TokPos: 8, TokEnd: 14},
+			}},
+		}},
+		{"meta", "bob @wrxst", []Mention{
+			{Nick: "Bob", Meta: "mod", Occurrences: []MentionOccurrence{{TokPos: 0, TokEnd: 3}}},
+			{Nick: "wrxst", Occurrences: []MentionOccurrence{{At: true, TokPos: 4, TokEnd: 10}}},
+		}},
+		{"spoiler", "||a @bob||", []Mention{
+			{Nick: "Bob", Meta: "mod", Occurrences: []MentionOccurrence{
+				{At: true, Spans: []SpanType{SpanSpoiler}, TokPos: 4, TokEnd: 8},
+			}},
+		}},
+		{"code", "`x @wrxst PEPE abeous` nsfw", []Mention{
+			{Nick: "wrxst", Occurrences: []MentionOccurrence{
+				{At: true, Spans: []SpanType{SpanCode}, TokPos: 3, TokEnd: 9},
+			}},
+			{Nick: "abeous", Occurrences: []MentionOccurrence{
+				{Spans: []SpanType{SpanCode}, TokPos: 15, TokEnd: 21},
+			}},
+		}},
+		{"code in spoiler", "> ||`wrxst`||", []Mention{
+			{Nick: "wrxst", Occurrences: []MentionOccurrence{
+				{Spans: []SpanType{SpanGreentext, SpanSpoiler, SpanCode}, TokPos: 5, TokEnd: 10},
+			}},
+		}},
+	}
+
+	for _, c := range cases {
+		msg := NewParser(ctx, NewLexer(c.input)).ParseMessage()
+		if mentions := Mentions(ctx, c.input, msg); !reflect.DeepEqual(c.expected, mentions) {
+			t.Errorf("%s: got %s expected %s", c.name, spew.Sdump(mentions), spew.Sdump(c.expected))
+		}
+	}
+}
+
+func TestMentionFlags(t *testing.T) {
+	ctx := newTestParserContext()
+
+	cases := []struct {
+		input                     string
+		explicit, notify, preview bool
+	}{
+		{"abeous", false, true, true},
+		{"@abeous", true, true, true},
+		{"||abeous||", false, true, false},
+		{"`@abeous`", true, false, false},
+		{"`abeous` abeous", false, true, true},
+		{"`abeous` ||abeous||", false, true, false},
+	}
+
+	for _, c := range cases {
+		msg := NewParser(ctx, NewLexer(c.input)).ParseMessage()
+		mentions := Mentions(ctx, c.input, msg)
+		if len(mentions) != 1 {
+			t.Errorf("%q: expected 1 mention, got %d", c.input, len(mentions))
+			continue
+		}
+		m := &mentions[0]
+		if m.Explicit() != c.explicit || m.Notify() != c.notify || m.Preview() != c.preview {
+			t.Errorf("%q: got explicit %t notify %t preview %t", c.input, m.Explicit(), m.Notify(), m.Preview())
+		}
+	}
+}
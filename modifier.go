@@ -0,0 +1,127 @@
+package parser
+
+import "sync"
+
+// EmoteModifierRule constrains how a modifier from EmoteModifiers may be
+// applied. Modifiers without a rule take no arguments and may be repeated.
+type EmoteModifierRule struct {
+	Name string
+	// MinArgs and MaxArgs bound the number of arguments. Arguments are passed
+	// either in parentheses, as in hue(120), or as a single value following a
+	// colon, as in speed:2.
+	MinArgs int
+	MaxArgs int
+	// MaxCount limits how many times the modifier can appear on one emote.
+	// Zero means unlimited.
+	MaxCount int
+	// Exclusive lists modifiers that cannot be combined with this one.
+	Exclusive []string
+	// Emotes lists the emotes the modifier can be applied to. Empty allows
+	// every emote.
+	Emotes []string
+}
+
+func NewEmoteModifierRuleIndex(rules []EmoteModifierRule) *EmoteModifierRuleIndex {
+	r := &EmoteModifierRuleIndex{}
+	r.Replace(rules)
+	return r
+}
+
+type EmoteModifierRuleIndex struct {
+	sync.Mutex
+	rules map[string]*EmoteModifierRule
+	buf   []byte
+}
+
+func (r *EmoteModifierRuleIndex) Get(v []rune) *EmoteModifierRule {
+	r.Lock()
+	defer r.Unlock()
+
+	r.buf = appendRuneBytes(r.buf[:0], v)
+	return r.rules[string(r.buf)]
+}
+
+func (r *EmoteModifierRuleIndex) getByName(name string) *EmoteModifierRule {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.rules[name]
+}
+
+func (r *EmoteModifierRuleIndex) Insert(rule EmoteModifierRule) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.rules[rule.Name] = &rule
+}
+
+func (r *EmoteModifierRuleIndex) Remove(v []rune) {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.rules, string(v))
+}
+
+func (r *EmoteModifierRuleIndex) Replace(rules []EmoteModifierRule) {
+	m := make(map[string]*EmoteModifierRule, len(rules))
+	for i := range rules {
+		rule := rules[i]
+		m[rule.Name] = &rule
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	r.rules = m
+}
+
+func containsString(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEmoteModifier reports whether modifier m with nargs arguments can be
+// added to e.
+func (p *Parser) checkEmoteModifier(e *Emote, m string, rule *EmoteModifierRule, nargs int, argsOK bool) (ModifierRejectReason, bool) {
+	if rule != nil {
+		if !argsOK || nargs < rule.MinArgs || nargs > rule.MaxArgs {
+			return ModifierBadArgs, false
+		}
+		if len(rule.Emotes) != 0 && !containsString(rule.Emotes, e.Name) {
+			return ModifierNotAllowed, false
+		}
+		if rule.MaxCount != 0 {
+			var n int
+			for _, o := range e.Modifiers {
+				if o == m {
+					n++
+				}
+			}
+			if n >= rule.MaxCount {
+				return ModifierTooMany, false
+			}
+		}
+		for _, o := range e.Modifiers {
+			if containsString(rule.Exclusive, o) {
+				return ModifierExclusive, false
+			}
+		}
+	}
+
+	if p.ctx.EmoteModifierRules != nil {
+		for _, o := range e.Modifiers {
+			if r := p.ctx.EmoteModifierRules.getByName(o); r != nil && containsString(r.Exclusive, m) {
+				return ModifierExclusive, false
+			}
+		}
+	}
+
+	if p.ctx.MaxEmoteModifiers != 0 && len(e.Modifiers) >= p.ctx.MaxEmoteModifiers {
+		return ModifierLimit, false
+	}
+	return 0, true
+}
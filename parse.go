@@ -10,12 +10,26 @@ import (
 
 func NewRuneIndex(values [][]rune) *RuneIndex {
 	sort.Sort(runeSlices(values))
-	return &RuneIndex{values: values}
+	return &RuneIndex{
+		values: values,
+		names:  runeSlicesToStrings(values),
+	}
 }
 
 type RuneIndex struct {
 	sync.Mutex
 	values [][]rune
+	// names holds the string form of each value so lookups can return a
+	// shared copy instead of allocating.
+	names []string
+}
+
+func runeSlicesToStrings(values [][]rune) []string {
+	names := make([]string, len(values))
+	for i, v := range values {
+		names[i] = string(v)
+	}
+	return names
 }
 
 func (r *RuneIndex) findIndex(v []rune) int {
@@ -42,15 +56,45 @@ func (r *RuneIndex) Contains(v []rune) bool {
 	return i != len(r.values) && compareRuneSlices(r.values[i], v) == 0
 }
 
+// Get returns the string form of v if it is in the index.
+func (r *RuneIndex) Get(v []rune) (string, bool) {
+	r.Lock()
+	defer r.Unlock()
+
+	i := r.findIndex(v)
+	if i != len(r.values) && compareRuneSlices(r.values[i], v) == 0 {
+		return r.names[i], true
+	}
+	return "", false
+}
+
+// MatchPrefix returns the values that start with prefix ignoring case.
+func (r *RuneIndex) MatchPrefix(prefix []rune) (matches []string) {
+	prefix = runeSliceToLower(prefix, nil)
+
+	r.Lock()
+	defer r.Unlock()
+
+	for i, v := range r.values {
+		if hasPrefixFold(v, 0, prefix) {
+			matches = append(matches, r.names[i])
+		}
+	}
+	return
+}
+
 func (r *RuneIndex) Insert(v []rune) {
 	r.Lock()
 	defer r.Unlock()
 
 	i := r.findIndex(v)
 	r.values = append(r.values, v)
+	r.names = append(r.names, string(v))
 	if i != len(r.values)-1 {
 		copy(r.values[i+1:], r.values[i:])
 		r.values[i] = v
+		copy(r.names[i+1:], r.names[i:])
+		r.names[i] = string(v)
 	}
 }
 
@@ -62,16 +106,20 @@ func (r *RuneIndex) Remove(v []rune) {
 	if i != len(r.values) {
 		copy(r.values[i:], r.values[i+1:])
 		r.values = r.values[:len(r.values)-1]
+		copy(r.names[i:], r.names[i+1:])
+		r.names = r.names[:len(r.names)-1]
 	}
 }
 
 func (r *RuneIndex) Replace(values [][]rune) {
 	sort.Sort(runeSlices(values))
+	names := runeSlicesToStrings(values)
 
 	r.Lock()
 	defer r.Unlock()
 
 	r.values = values
+	r.names = names
 }
 
 type runeSlices [][]rune
@@ -93,11 +141,14 @@ func compareRuneSlices(a, b []rune) int {
 }
 
 func NewNickIndex(values [][]rune) *NickIndex {
-	n := &NickIndex{
-		values:   llrb.New(),
+	return &NickIndex{
+		values:   newNickTree(values),
 		tempItem: &nickIndexItem{},
 	}
+}
 
+func newNickTree(values [][]rune) *llrb.LLRB {
+	t := llrb.New()
 	items := make([]llrb.Item, len(values))
 	for i, v := range values {
 		items[i] = &nickIndexItem{
@@ -105,9 +156,8 @@ func NewNickIndex(values [][]rune) *NickIndex {
 			nick: string(v),
 		}
 	}
-	n.values.InsertNoReplaceBulk(items...)
-
-	return n
+	t.InsertNoReplaceBulk(items...)
+	return t
 }
 
 type NickIndex struct {
@@ -136,6 +186,26 @@ func (n *NickIndex) Get(v []rune) *nickIndexItem {
 	return it
 }
 
+// MatchPrefix returns the nicks that start with prefix ignoring case.
+func (n *NickIndex) MatchPrefix(prefix []rune) (matches []string) {
+	prefix = runeSliceToLower(prefix, nil)
+
+	n.Lock()
+	defer n.Unlock()
+
+	min := n.values.Min()
+	if min == nil {
+		return
+	}
+	n.values.AscendGreaterOrEqual(min, func(i llrb.Item) bool {
+		if it := i.(*nickIndexItem); hasPrefixFold(it.key, 0, prefix) {
+			matches = append(matches, it.nick)
+		}
+		return true
+	})
+	return
+}
+
 func (n *NickIndex) Insert(v []rune) {
 	n.InsertWithMeta(v, nil)
 }
@@ -151,6 +221,23 @@ func (n *NickIndex) InsertWithMeta(v []rune, m interface{}) {
 	})
 }
 
+// InsertNoReplace inserts v unless the index already holds it, in which
+// case the existing nick and its metadata are kept. It reports whether v was
+// inserted.
+func (n *NickIndex) InsertNoReplace(v []rune) bool {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.values.Has(n.useTempItem(v)) {
+		return false
+	}
+	n.values.ReplaceOrInsert(&nickIndexItem{
+		key:  runeSliceToLower(v, nil),
+		nick: string(v),
+	})
+	return true
+}
+
 func (n *NickIndex) Remove(v []rune) {
 	n.Lock()
 	defer n.Unlock()
@@ -158,6 +245,26 @@ func (n *NickIndex) Remove(v []rune) {
 	n.values.Delete(n.useTempItem(v))
 }
 
+// Replace sets the nicks in the index to values. Nicks that were already in
+// the index keep their metadata.
+func (n *NickIndex) Replace(values [][]rune) {
+	t := newNickTree(values)
+
+	n.Lock()
+	defer n.Unlock()
+
+	if min := t.Min(); min != nil {
+		t.AscendGreaterOrEqual(min, func(i llrb.Item) bool {
+			it := i.(*nickIndexItem)
+			if prev, ok := n.values.Get(it).(*nickIndexItem); ok {
+				it.meta = prev.meta
+			}
+			return true
+		})
+	}
+	n.values = t
+}
+
 func runeSliceToLower(src, dst []rune) []rune {
 	if cap(dst) < len(src) {
 		dst = make([]rune, len(src))
@@ -196,59 +303,405 @@ func RunesFromStrings(s []string) (r [][]rune) {
 	return
 }
 
+func NewChannelIndex(values [][]rune) *ChannelIndex {
+	return &ChannelIndex{index: NewNickIndex(values)}
+}
+
+// ChannelInfo holds the metadata attached to channel references.
+type ChannelInfo struct {
+	DisplayName string
+	Live        bool
+}
+
+// ChannelIndex is a case insensitive index of channel names and their
+// metadata.
+type ChannelIndex struct {
+	index *NickIndex
+}
+
+func (c *ChannelIndex) Contains(v []rune) bool {
+	return c.index.Contains(v)
+}
+
+// Get returns the canonical name and metadata of a channel.
+func (c *ChannelIndex) Get(v []rune) (string, ChannelInfo, bool) {
+	it := c.index.Get(v)
+	if it == nil {
+		return "", ChannelInfo{}, false
+	}
+	info, _ := it.meta.(ChannelInfo)
+	return it.nick, info, true
+}
+
+// MatchPrefix returns the channels that start with prefix ignoring case.
+func (c *ChannelIndex) MatchPrefix(prefix []rune) []string {
+	return c.index.MatchPrefix(prefix)
+}
+
+func (c *ChannelIndex) Insert(v []rune) {
+	c.InsertWithInfo(v, ChannelInfo{})
+}
+
+func (c *ChannelIndex) InsertWithInfo(v []rune, info ChannelInfo) {
+	c.index.InsertWithMeta(v, info)
+}
+
+func (c *ChannelIndex) Remove(v []rune) {
+	c.index.Remove(v)
+}
+
+func NewShortcodeIndex(values map[string]string) *ShortcodeIndex {
+	s := &ShortcodeIndex{values: make(map[string]string, len(values))}
+	for k, v := range values {
+		s.values[k] = v
+	}
+	return s
+}
+
+// ShortcodeIndex maps shortcode names like "thumbsup" to the emoji or emote
+// they are replaced with.
+type ShortcodeIndex struct {
+	sync.Mutex
+	values map[string]string
+	buf    []byte
+}
+
+func (s *ShortcodeIndex) Get(v []rune) (string, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.buf = appendRuneBytes(s.buf[:0], v)
+	r, ok := s.values[string(s.buf)]
+	return r, ok
+}
+
+// MatchPrefix returns the shortcodes that start with prefix ignoring case.
+func (s *ShortcodeIndex) MatchPrefix(prefix []rune) (matches []string) {
+	prefix = runeSliceToLower(prefix, nil)
+
+	s.Lock()
+	defer s.Unlock()
+
+	for k := range s.values {
+		if hasPrefixFold([]rune(k), 0, prefix) {
+			matches = append(matches, k)
+		}
+	}
+	return
+}
+
+func (s *ShortcodeIndex) Insert(v []rune, r string) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.values[string(v)] = r
+}
+
+func (s *ShortcodeIndex) Remove(v []rune) {
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.values, string(v))
+}
+
+func (s *ShortcodeIndex) Replace(values map[string]string) {
+	n := make(map[string]string, len(values))
+	for k, v := range values {
+		n[k] = v
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.values = n
+}
+
 type ParserContextValues struct {
-	Emotes         []string
-	EmoteModifiers []string
-	Nicks          []string
-	Tags           []string
+	Emotes             []string
+	EmoteModifiers     []string
+	EmoteModifierRules []EmoteModifierRule
+	MaxEmoteModifiers  int
+	Nicks              []string
+	Tags               []string
+	Shortcodes         map[string]string
+	Channels           []string
+	Commands           []string
+	LinkPolicy         LinkPolicyValues
+	Limits             ParseLimits
 }
 
 func NewParserContext(opt ParserContextValues) *ParserContext {
 	return &ParserContext{
-		Emotes:         NewRuneIndex(RunesFromStrings(opt.Emotes)),
-		EmoteModifiers: NewRuneIndex(RunesFromStrings(opt.EmoteModifiers)),
-		Nicks:          NewNickIndex(RunesFromStrings(opt.Nicks)),
-		Tags:           NewRuneIndex(RunesFromStrings(opt.Tags)),
+		Emotes:             NewRuneIndex(RunesFromStrings(opt.Emotes)),
+		EmoteModifiers:     NewRuneIndex(RunesFromStrings(opt.EmoteModifiers)),
+		EmoteModifierRules: NewEmoteModifierRuleIndex(opt.EmoteModifierRules),
+		MaxEmoteModifiers:  opt.MaxEmoteModifiers,
+		Nicks:              NewNickIndex(RunesFromStrings(opt.Nicks)),
+		Tags:               NewRuneIndex(RunesFromStrings(opt.Tags)),
+		Shortcodes:         NewShortcodeIndex(opt.Shortcodes),
+		Channels:           NewChannelIndex(RunesFromStrings(opt.Channels)),
+		Commands:           NewRuneIndex(RunesFromStrings(opt.Commands)),
+		LinkPolicy:         NewLinkPolicy(opt.LinkPolicy),
+		Limits:             opt.Limits,
 	}
 }
 
 type ParserContext struct {
-	Emotes         *RuneIndex
-	EmoteModifiers *RuneIndex
-	Nicks          *NickIndex
-	Tags           *RuneIndex
+	Emotes             *RuneIndex
+	EmoteModifiers     *RuneIndex
+	EmoteModifierRules *EmoteModifierRuleIndex
+	// MaxEmoteModifiers caps the number of modifiers applied to one emote.
+	// Zero means unlimited.
+	MaxEmoteModifiers int
+	Nicks             *NickIndex
+	Tags              *RuneIndex
+	Shortcodes        *ShortcodeIndex
+	Channels          *ChannelIndex
+	// Commands lists the chat commands offered for completion. It does not
+	// affect parsing.
+	Commands   *RuneIndex
+	LinkPolicy *LinkPolicy
+	Limits     ParseLimits
+}
+
+// ParseLimits bound the work done parsing a single message. Once a limit is
+// reached the rest of the message is treated as text, except for MaxDepth
+// which only stops spans from nesting further. Zero values are unlimited.
+// Reset and ResetBytes stop decoding input past MaxRunes.
+type ParseLimits struct {
+	MaxRunes  int
+	MaxNodes  int
+	MaxDepth  int
+	MaxTokens int
+}
+
+// LimitFlag records which parse limits a message exceeded.
+type LimitFlag int
+
+const (
+	LimitRunes LimitFlag = 1 << iota
+	LimitNodes
+	LimitDepth
+	LimitTokens
+)
+
+var limitFlagNames = []string{"Runes", "Nodes", "Depth", "Tokens"}
+
+func (f LimitFlag) String() string {
+	var s string
+	for i, n := range limitFlagNames {
+		if f&(1<<uint(i)) != 0 {
+			if s != "" {
+				s += "|"
+			}
+			s += n
+		}
+	}
+	return s
 }
 
 var meCmd = []rune("me")
 
+const maxShortcodeLen = 32
+
 func NewParser(ctx *ParserContext, l lexer) *Parser {
+	l.truncate(ctx.Limits.MaxRunes)
 	return &Parser{
 		ctx:   ctx,
 		lexer: l,
 	}
 }
 
+// NewReusableParser returns a parser for parsing many messages in sequence.
+// Set the input with Reset before each call to ParseMessage. Buffers and
+// nodes are recycled between messages, so an AST returned by ParseMessage is
+// only valid until the next Reset. Links and nodes found by shortcode are
+// still allocated for every message.
+func NewReusableParser(ctx *ParserContext) *Parser {
+	return &Parser{
+		ctx:   ctx,
+		arena: &nodeArena{},
+	}
+}
+
 type Parser struct {
 	ctx   *ParserContext
 	lexer lexer
+	arena *nodeArena
 
 	pos int
 	tok tokType
 	lit []rune
+
+	// tags are the tags parsed so far. They apply to every following link.
+	tags []string
+
+	tokens  int
+	nodes   int
+	depth   int
+	limited LimitFlag
+
+	// checkpoints records where parsing can resume after an edit. It is only
+	// set by IncrementalParser.
+	checkpoints []parseCheckpoint
+}
+
+// Reset prepares the parser to parse input, reusing the memory allocated for
+// previous messages.
+func (p *Parser) Reset(input string) {
+	p.lexer.reset(input, p.ctx.Limits.MaxRunes)
+	p.reset()
+}
+
+// ResetBytes is Reset for UTF-8 encoded input.
+func (p *Parser) ResetBytes(input []byte) {
+	p.lexer.resetBytes(input, p.ctx.Limits.MaxRunes)
+	p.reset()
+}
+
+// ByteOffset converts a node position into a byte offset in the input.
+func (p *Parser) ByteOffset(pos int) int {
+	return p.lexer.byteOffset(pos)
+}
+
+func (p *Parser) reset() {
+	p.lexer.truncate(p.ctx.Limits.MaxRunes)
+	if p.arena != nil {
+		p.arena.reset()
+	}
+
+	p.pos = 0
+	p.tok = tokEOF
+	p.lit = nil
+	p.tags = p.tags[:0]
+	p.tokens = 0
+	p.nodes = 0
+	p.depth = 0
+	p.limited = 0
+}
+
+func (p *Parser) newSpan() *Span {
+	if p.arena != nil {
+		return p.arena.span()
+	}
+	return &Span{}
+}
+
+func (p *Parser) newEmote() *Emote {
+	if p.arena != nil {
+		return p.arena.emote()
+	}
+	return &Emote{}
+}
+
+func (p *Parser) newNick() *Nick {
+	if p.arena != nil {
+		return p.arena.nick()
+	}
+	return &Nick{}
+}
+
+func (p *Parser) newTag() *Tag {
+	if p.arena != nil {
+		return p.arena.tag()
+	}
+	return &Tag{}
+}
+
+func (p *Parser) newEmoji() *Emoji {
+	if p.arena != nil {
+		return p.arena.emoji()
+	}
+	return &Emoji{}
+}
+
+func (p *Parser) newChannelRef() *ChannelRef {
+	if p.arena != nil {
+		return p.arena.channel()
+	}
+	return &ChannelRef{}
 }
 
 func (p *Parser) next() {
+	if p.limited&^LimitDepth != 0 {
+		return
+	}
+
 	t := p.lexer.Next()
+	p.tokens++
+
+	if f := p.exceeds(t, p.tokens); f != 0 {
+		p.limit(f)
+	} else {
+		p.setToken(t)
+	}
+}
+
+// exceeds returns the limit exceeded by t when it is the n-th token of the
+// message.
+func (p *Parser) exceeds(t token, n int) LimitFlag {
+	lim := &p.ctx.Limits
+	if lim.MaxTokens != 0 && n > lim.MaxTokens {
+		return LimitTokens
+	} else if lim.MaxRunes != 0 && t.pos+len(t.val) > lim.MaxRunes {
+		return LimitRunes
+	}
+	return 0
+}
+
+// lookahead reads the next token from l, a copy of the parser's lexer, and
+// counts it in n. It returns false if the token exceeds a limit, in which
+// case the lookahead must be abandoned so the limit is recorded by next.
+func (p *Parser) lookahead(l *lexer, n *int) (token, bool) {
+	t := l.Next()
+	*n++
+	return t, p.exceeds(t, p.tokens+*n) == 0
+}
+
+// limit stops parsing by skipping to the end of the input.
+func (p *Parser) limit(f LimitFlag) {
+	p.limited |= f
+	p.tok = tokEOF
+	p.pos = p.lexer.size
+	p.lit = nil
+}
+
+// allocNode counts a node against the node limit and reports whether it can
+// be added.
+func (p *Parser) allocNode() bool {
+	if max := p.ctx.Limits.MaxNodes; max != 0 && p.nodes >= max {
+		p.limit(LimitNodes)
+		return false
+	}
+	p.nodes++
+	return true
+}
+
+// allowNesting reports whether a span can be opened at the current depth.
+func (p *Parser) allowNesting() bool {
+	if max := p.ctx.Limits.MaxDepth; max != 0 && p.depth >= max {
+		p.limited |= LimitDepth
+		return false
+	}
+	return true
+}
+
+func (p *Parser) insert(s *Span, n Node) {
+	if p.allocNode() {
+		s.Insert(n)
+	}
+}
+
+func (p *Parser) setToken(t token) {
 	p.tok = t.typ
 	p.pos = t.pos
 	p.lit = t.val
 }
 
-func (p *Parser) parseEmote() (e *Emote) {
-	e = &Emote{
-		Name:   string(p.lit),
-		TokPos: p.pos,
-	}
+func (p *Parser) parseEmote(name string) (e *Emote) {
+	e = p.newEmote()
+	e.Name = name
+	e.TokPos = p.pos
 
 	for {
 		p.next()
@@ -259,18 +712,126 @@ func (p *Parser) parseEmote() (e *Emote) {
 		}
 		p.next()
 
-		if !p.ctx.EmoteModifiers.Contains(p.lit) {
+		m, ok := p.ctx.EmoteModifiers.Get(p.lit)
+		if !ok {
 			return
 		}
-		e.InsertModifier(string(p.lit))
+		p.parseEmoteModifier(e, m)
 	}
 }
 
-func (p *Parser) parseTag() (t *Tag) {
-	t = &Tag{
-		Name:   string(p.lit),
+func (p *Parser) parseEmoteModifier(e *Emote, m string) {
+	pos := p.pos
+
+	var rule *EmoteModifierRule
+	if p.ctx.EmoteModifierRules != nil {
+		rule = p.ctx.EmoteModifierRules.Get(p.lit)
+	}
+
+	var args []string
+	argsOK := true
+	if rule != nil && rule.MaxArgs > 0 {
+		args, argsOK = p.parseEmoteModifierArgs()
+	}
+
+	if reason, ok := p.checkEmoteModifier(e, m, rule, len(args), argsOK); !ok {
+		e.InsertRejected(&RejectedModifier{
+			Name:   m,
+			Args:   args,
+			Reason: reason,
+			TokPos: pos,
+			TokEnd: p.pos + len(p.lit),
+		})
+		return
+	}
+	e.InsertModifierWithArgs(m, args)
+}
+
+// parseEmoteModifierArgs reads the arguments following a modifier. It returns
+// false without advancing when a parenthesized argument list is not closed.
+func (p *Parser) parseEmoteModifierArgs() (args []string, ok bool) {
+	l := p.lexer
+	var n int
+	t, ok := p.lookahead(&l, &n)
+	if !ok {
+		return nil, true
+	}
+
+	switch {
+	case t.typ == tokPunct && t.val[0] == '(':
+		args = []string{}
+		start := t.pos + 1
+		for {
+			if t, ok = p.lookahead(&l, &n); !ok {
+				return nil, false
+			}
+			if t.typ == tokPunct && (t.val[0] == ',' || t.val[0] == ')') {
+				if t.val[0] == ',' || t.pos != start || len(args) != 0 {
+					args = append(args, string(l.input[start:t.pos]))
+				}
+				if t.val[0] == ')' {
+					break
+				}
+				start = t.pos + 1
+			} else if t.typ != tokWord && t.typ != tokPunct && t.typ != tokHash {
+				return nil, false
+			}
+		}
+	case t.typ == tokColon:
+		t, ok = p.lookahead(&l, &n)
+		if !ok || t.typ != tokWord || p.ctx.EmoteModifiers.Contains(t.val) {
+			return nil, true
+		}
+		args = []string{string(t.val)}
+	default:
+		return nil, true
+	}
+
+	p.lexer = l
+	p.tokens += n
+	p.setToken(t)
+	return args, true
+}
+
+func (p *Parser) parseEmoji() (e *Emoji) {
+	e = p.newEmoji()
+	e.Codepoints = p.lit
+	e.TokPos = p.pos
+	if p.arena == nil {
+		e.Codepoints = append([]rune(nil), p.lit...)
+	}
+
+	p.next()
+
+	e.TokEnd = p.pos
+	return
+}
+
+func (p *Parser) parseLink() (l *Link) {
+	l = &Link{
+		URL:    string(p.lit),
 		TokPos: p.pos,
 	}
+	if p.ctx.LinkPolicy != nil {
+		l.Class = p.ctx.LinkPolicy.Classify(l.URL)
+	}
+	if len(p.tags) != 0 {
+		l.Tags = append([]string(nil), p.tags...)
+	}
+
+	p.next()
+
+	l.TokEnd = p.pos
+	return
+}
+
+func (p *Parser) parseTag(name string) (t *Tag) {
+	t = p.newTag()
+	t.Name = name
+	t.TokPos = p.pos
+	if !containsString(p.tags, t.Name) {
+		p.tags = append(p.tags, t.Name)
+	}
 
 	p.next()
 
@@ -279,11 +840,10 @@ func (p *Parser) parseTag() (t *Tag) {
 }
 
 func (p *Parser) parseNick(it *nickIndexItem) (n *Nick) {
-	n = &Nick{
-		Nick:   it.nick,
-		TokPos: p.pos,
-		Meta:   it.meta,
-	}
+	n = p.newNick()
+	n.Nick = it.nick
+	n.TokPos = p.pos
+	n.Meta = it.meta
 
 	p.next()
 
@@ -304,11 +864,92 @@ func (p *Parser) tryParseAtNick() (n *Nick) {
 	return
 }
 
-func (p *Parser) parseCode() (s *Span) {
-	s = &Span{
-		Type:   SpanCode,
-		TokPos: p.pos,
+func (p *Parser) tryParseChannelRef() (c *ChannelRef) {
+	if p.ctx.Channels == nil {
+		p.next()
+		return
+	}
+
+	pos := p.pos
+
+	p.next()
+
+	if name, info, ok := p.ctx.Channels.Get(p.lit); ok {
+		c = p.newChannelRef()
+		c.Channel = name
+		c.DisplayName = info.DisplayName
+		c.Live = info.Live
+		c.TokPos = pos
+		if c.DisplayName == "" {
+			c.DisplayName = name
+		}
+
+		p.next()
+
+		c.TokEnd = p.pos
+	}
+
+	return
+}
+
+// tryParseShortcode looks ahead from a colon for a shortcode like :thumbsup:
+// and returns the emote or emoji it maps to. The parser only advances when a
+// known shortcode is found.
+func (p *Parser) tryParseShortcode() (n Node) {
+	if p.ctx.Shortcodes == nil {
+		return
+	}
+
+	pos := p.pos
+	l := p.lexer
+	var tokens int
+	for {
+		t, ok := p.lookahead(&l, &tokens)
+		if !ok || t.pos+len(t.val)-pos-1 > maxShortcodeLen {
+			return
+		}
+
+		switch t.typ {
+		case tokWord, tokPunct:
+			continue
+		case tokColon:
+			if t.pos == pos+1 {
+				return
+			}
+		default:
+			return
+		}
+
+		v, ok := p.ctx.Shortcodes.Get(l.input[pos+1 : t.pos])
+		if !ok {
+			return
+		}
+
+		p.lexer = l
+		p.tokens += tokens
+		p.next()
+
+		if r := []rune(v); p.ctx.Emotes.Contains(r) {
+			n = &Emote{
+				Name:   v,
+				TokPos: pos,
+				TokEnd: p.pos,
+			}
+		} else {
+			n = &Emoji{
+				Codepoints: r,
+				TokPos:     pos,
+				TokEnd:     p.pos,
+			}
+		}
+		return
 	}
+}
+
+func (p *Parser) parseCode() (s *Span) {
+	s = p.newSpan()
+	s.Type = SpanCode
+	s.TokPos = p.pos
 
 	for p.tok != tokEOF {
 		p.next()
@@ -323,10 +964,9 @@ func (p *Parser) parseCode() (s *Span) {
 }
 
 func (p *Parser) parseSpan(t SpanType) (s *Span) {
-	s = &Span{
-		Type:   t,
-		TokPos: p.pos,
-	}
+	s = p.newSpan()
+	s.Type = t
+	s.TokPos = p.pos
 
 	p.next()
 
@@ -346,6 +986,13 @@ func (p *Parser) parseSpan(t SpanType) (s *Span) {
 		}
 	}
 
+	p.parseSpanBody(s, t)
+	return
+}
+
+// parseSpanBody parses the nodes of s up to its closing marker or the end of
+// the input.
+func (p *Parser) parseSpanBody(s *Span, t SpanType) {
 	for {
 		switch p.tok {
 		case tokEOF:
@@ -357,23 +1004,52 @@ func (p *Parser) parseSpan(t SpanType) (s *Span) {
 				s.TokEnd = p.pos
 				return
 			}
-			s.Insert(p.parseSpan(SpanSpoiler))
+			if !p.allowNesting() {
+				p.next()
+			} else if p.allocNode() {
+				p.depth++
+				s.Insert(p.parseSpan(SpanSpoiler))
+				p.depth--
+			}
 		case tokBacktick:
-			s.Insert(p.parseCode())
+			if !p.allowNesting() {
+				p.next()
+			} else if p.allocNode() {
+				s.Insert(p.parseCode())
+			}
 		case tokAt:
 			if n := p.tryParseAtNick(); n != nil {
-				s.Insert(n)
+				p.insert(s, n)
 			}
+		case tokHash:
+			if c := p.tryParseChannelRef(); c != nil {
+				p.insert(s, c)
+			}
+		case tokColon:
+			if n := p.tryParseShortcode(); n != nil {
+				p.insert(s, n)
+			} else {
+				p.next()
+			}
+		case tokEmoji:
+			p.insert(s, p.parseEmoji())
+		case tokLink:
+			p.insert(s, p.parseLink())
 		case tokWord:
-			if p.ctx.Tags.Contains(p.lit) {
-				s.Insert(p.parseTag())
-			} else if p.ctx.Emotes.Contains(p.lit) {
-				s.Insert(p.parseEmote())
+			if name, ok := p.ctx.Tags.Get(p.lit); ok {
+				p.insert(s, p.parseTag(name))
+			} else if name, ok := p.ctx.Emotes.Get(p.lit); ok {
+				p.insert(s, p.parseEmote(name))
 			} else if it := p.ctx.Nicks.Get(p.lit); it != nil {
-				s.Insert(p.parseNick(it))
+				p.insert(s, p.parseNick(it))
 			} else {
 				p.next()
 			}
+		case tokWhitespace:
+			if t == SpanMessage && p.checkpoints != nil {
+				p.checkpoint(s)
+			}
+			p.next()
 		default:
 			p.next()
 		}
@@ -381,5 +1057,10 @@ func (p *Parser) parseSpan(t SpanType) (s *Span) {
 }
 
 func (p *Parser) ParseMessage() (s *Span) {
-	return p.parseSpan(SpanMessage)
+	s = p.parseSpan(SpanMessage)
+	s.Limited = p.limited
+	if p.arena != nil {
+		p.arena.finish()
+	}
+	return
 }
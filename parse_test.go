@@ -518,10 +518,69 @@ var parseTests = []parseTest{
 		TokEnd: 13,
 	}},
 	{"emoji", "🙈🙉🙊", &Span{
-		Type:   SpanMessage,
+		Type: SpanMessage,
+		Nodes: []Node{
+			&Emoji{
+				Codepoints: []rune("🙈"),
+				TokPos:     0,
+				TokEnd:     1,
+			},
+			&Emoji{
+				Codepoints: []rune("🙉"),
+				TokPos:     1,
+				TokEnd:     2,
+			},
+			&Emoji{
+				Codepoints: []rune("🙊"),
+				TokPos:     2,
+				TokEnd:     3,
+			},
+		},
 		TokPos: 0,
 		TokEnd: 3,
 	}},
+	{"emoji next to emote", "PEPE👍🏽PEPE:wide", &Span{
+		Type: SpanMessage,
+		Nodes: []Node{
+			&Emote{
+				Name:   "PEPE",
+				TokPos: 0,
+				TokEnd: 4,
+			},
+			&Emoji{
+				Codepoints: []rune("👍🏽"),
+				TokPos:     4,
+				TokEnd:     6,
+			},
+			&Emote{
+				Name: "PEPE",
+				Modifiers: []string{
+					"wide",
+				},
+				TokPos: 6,
+				TokEnd: 15,
+			},
+		},
+		TokPos: 0,
+		TokEnd: 15,
+	}},
+	{"emoji next to nick", "@abeous🇺🇸", &Span{
+		Type: SpanMessage,
+		Nodes: []Node{
+			&Nick{
+				Nick:   "abeous",
+				TokPos: 0,
+				TokEnd: 7,
+			},
+			&Emoji{
+				Codepoints: []rune("🇺🇸"),
+				TokPos:     7,
+				TokEnd:     9,
+			},
+		},
+		TokPos: 0,
+		TokEnd: 9,
+	}},
 	{"non ascii words", "日本語のテキスト", &Span{
 		Type:   SpanMessage,
 		TokPos: 0,
@@ -583,15 +642,174 @@ var parseTests = []parseTest{
 		TokPos: 0,
 		TokEnd: 1,
 	}},
+	{"shortcodes", "nice :thumbsup: :+1::pepe:", &Span{
+		Type: SpanMessage,
+		Nodes: []Node{
+			&Emoji{
+				Codepoints: []rune("👍"),
+				TokPos:     5,
+				TokEnd:     15,
+			},
+			&Emoji{
+				Codepoints: []rune("👍"),
+				TokPos:     16,
+				TokEnd:     20,
+			},
+			&Emote{
+				Name:   "PEPE",
+				TokPos: 20,
+				TokEnd: 26,
+			},
+		},
+		TokPos: 0,
+		TokEnd: 26,
+	}},
+	{"channels", "#strims and #DESTINY #unknown", &Span{
+		Type: SpanMessage,
+		Nodes: []Node{
+			&ChannelRef{
+				Channel:     "strims",
+				DisplayName: "strims",
+				TokPos:      0,
+				TokEnd:      7,
+			},
+			&ChannelRef{
+				Channel:     "destiny",
+				DisplayName: "Destiny",
+				Live:        true,
+				TokPos:      12,
+				TokEnd:      20,
+			},
+		},
+		TokPos: 0,
+		TokEnd: 29,
+	}},
+	{"links", "PEPE https://strims.gg/PEPE ||www.example.com||", &Span{
+		Type: SpanMessage,
+		Nodes: []Node{
+			&Emote{
+				Name:   "PEPE",
+				TokPos: 0,
+				TokEnd: 4,
+			},
+			&Link{
+				URL:    "https://strims.gg/PEPE",
+				Class:  LinkAllowed,
+				TokPos: 5,
+				TokEnd: 27,
+			},
+			&Span{
+				Type: SpanSpoiler,
+				Nodes: []Node{
+					&Link{
+						URL:    "www.example.com",
+						Class:  LinkAllowed,
+						TokPos: 30,
+						TokEnd: 45,
+					},
+				},
+				TokPos: 28,
+				TokEnd: 47,
+			},
+		},
+		TokPos: 0,
+		TokEnd: 47,
+	}},
+	{"tagged links", "https://a.com nsfw ||https://b.com|| nsfl https://c.com nsfw", &Span{
+		Type: SpanMessage,
+		Nodes: []Node{
+			&Link{
+				URL:    "https://a.com",
+				Class:  LinkAllowed,
+				TokPos: 0,
+				TokEnd: 13,
+			},
+			&Tag{
+				Name:   "nsfw",
+				TokPos: 14,
+				TokEnd: 18,
+			},
+			&Span{
+				Type: SpanSpoiler,
+				Nodes: []Node{
+					&Link{
+						URL:    "https://b.com",
+						Class:  LinkAllowed,
+						Tags:   []string{"nsfw"},
+						TokPos: 21,
+						TokEnd: 34,
+					},
+				},
+				TokPos: 19,
+				TokEnd: 36,
+			},
+			&Tag{
+				Name:   "nsfl",
+				TokPos: 37,
+				TokEnd: 41,
+			},
+			&Link{
+				URL:    "https://c.com",
+				Class:  LinkAllowed,
+				Tags:   []string{"nsfw", "nsfl"},
+				TokPos: 42,
+				TokEnd: 55,
+			},
+			&Tag{
+				Name:   "nsfw",
+				TokPos: 56,
+				TokEnd: 60,
+			},
+		},
+		TokPos: 0,
+		TokEnd: 60,
+	}},
+	{"unknown shortcode", ":nope: :thumbsup", &Span{
+		Type:   SpanMessage,
+		TokPos: 0,
+		TokEnd: 16,
+	}},
+	{"shortcode after emote modifier", "PEPE:wide:thumbsup: PEPE:thumbsup:", &Span{
+		Type: SpanMessage,
+		Nodes: []Node{
+			&Emote{
+				Name: "PEPE",
+				Modifiers: []string{
+					"wide",
+				},
+				TokPos: 0,
+				TokEnd: 9,
+			},
+			&Emote{
+				Name:   "PEPE",
+				TokPos: 20,
+				TokEnd: 24,
+			},
+		},
+		TokPos: 0,
+		TokEnd: 34,
+	}},
 }
 
-func TestParse(t *testing.T) {
+func newTestParserContext() *ParserContext {
 	ctx := NewParserContext(ParserContextValues{
 		Emotes:         []string{"PEPE", "CuckCrab"},
 		EmoteModifiers: []string{"wide", "rustle", "spin"},
 		Nicks:          []string{"abeous", "jeanpierrepratt", "wrxst"},
-		Tags:           []string{"nsfw"},
+		Tags:           []string{"nsfw", "nsfl"},
+		Shortcodes: map[string]string{
+			"thumbsup": "👍",
+			"+1":       "👍",
+			"pepe":     "PEPE",
+		},
+		Channels: []string{"strims"},
 	})
+	ctx.Channels.InsertWithInfo([]rune("destiny"), ChannelInfo{DisplayName: "Destiny", Live: true})
+	return ctx
+}
+
+func TestParse(t *testing.T) {
+	ctx := newTestParserContext()
 
 	for _, test := range parseTests {
 		p := NewParser(ctx, NewLexer(test.input))
@@ -607,6 +825,346 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestReusableParser(t *testing.T) {
+	p := NewReusableParser(newTestParserContext())
+
+	for i := 0; i < 2; i++ {
+		for _, test := range parseTests {
+			p.Reset(test.input)
+			ast := p.ParseMessage()
+
+			if !reflect.DeepEqual(test.ast, ast) {
+				t.Errorf("%s: got\n%s\nexpected\n%s", test.name, spew.Sdump(ast), spew.Sdump(test.ast))
+			}
+		}
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	p := NewReusableParser(newTestParserContext())
+
+	for _, test := range parseTests {
+		p.ResetBytes([]byte(test.input))
+		ast := p.ParseMessage()
+
+		if !reflect.DeepEqual(test.ast, ast) {
+			t.Errorf("%s: got\n%s\nexpected\n%s", test.name, spew.Sdump(ast), spew.Sdump(test.ast))
+		}
+	}
+
+	input := "ü PEPE"
+	p.ResetBytes([]byte(input))
+	e := p.ParseMessage().Nodes[0].(*Emote)
+	if pos, end := p.ByteOffset(e.TokPos), p.ByteOffset(e.TokEnd); input[pos:end] != "PEPE" {
+		t.Errorf("expected byte offsets of PEPE, got %d:%d", pos, end)
+	}
+}
+
+func TestReusableParserAllocs(t *testing.T) {
+	samples := readCorpus(300)
+	ctx := newTestParserContext()
+	p := NewReusableParser(ctx)
+
+	for _, s := range samples {
+		p.Reset(s)
+		p.ParseMessage()
+	}
+
+	var i int
+	allocs := testing.AllocsPerRun(len(samples), func() {
+		p.Reset(samples[i%len(samples)])
+		p.ParseMessage()
+		i++
+	})
+	if allocs > 0.1 {
+		t.Errorf("expected reused parser not to allocate, got %f allocs per message", allocs)
+	}
+
+	for _, s := range []string{"nsfw PEPE:wide ||#destiny 👍 @abeous||", "> `code` #strims 🏳️‍🌈 nsfl"} {
+		allocs := testing.AllocsPerRun(10, func() {
+			p.Reset(s)
+			p.ParseMessage()
+		})
+		if allocs != 0 {
+			t.Errorf("%q: expected reused parser not to allocate, got %f allocs", s, allocs)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	cases := []struct {
+		name   string
+		limits ParseLimits
+		input  string
+		ast    *Span
+	}{
+		{"runes", ParseLimits{MaxRunes: 10}, "PEPE PEPE PEPE", &Span{
+			Type: SpanMessage,
+			Nodes: []Node{
+				&Emote{
+					Name:   "PEPE",
+					TokPos: 0,
+					TokEnd: 4,
+				},
+				&Emote{
+					Name:   "PEPE",
+					TokPos: 5,
+					TokEnd: 9,
+				},
+			},
+			TokPos:  0,
+			TokEnd:  14,
+			Limited: LimitRunes,
+		}},
+		{"nodes", ParseLimits{MaxNodes: 1}, "PEPE ||PEPE||", &Span{
+			Type: SpanMessage,
+			Nodes: []Node{
+				&Emote{
+					Name:   "PEPE",
+					TokPos: 0,
+					TokEnd: 4,
+				},
+			},
+			TokPos:  0,
+			TokEnd:  13,
+			Limited: LimitNodes,
+		}},
+		{"depth", ParseLimits{MaxDepth: 1}, "||a `b`|| `c`", &Span{
+			Type: SpanMessage,
+			Nodes: []Node{
+				&Span{
+					Type:   SpanSpoiler,
+					TokPos: 0,
+					TokEnd: 9,
+				},
+				&Span{
+					Type:   SpanCode,
+					TokPos: 10,
+					TokEnd: 13,
+				},
+			},
+			TokPos:  0,
+			TokEnd:  13,
+			Limited: LimitDepth,
+		}},
+		{"tokens", ParseLimits{MaxTokens: 3}, "a b PEPE", &Span{
+			Type:    SpanMessage,
+			TokPos:  0,
+			TokEnd:  8,
+			Limited: LimitTokens,
+		}},
+		{"shortcode runes", ParseLimits{MaxRunes: 5}, ":thumbsup:", &Span{
+			Type:    SpanMessage,
+			TokPos:  0,
+			TokEnd:  10,
+			Limited: LimitRunes,
+		}},
+		{"shortcode tokens", ParseLimits{MaxTokens: 2}, ":thumbsup:", &Span{
+			Type:    SpanMessage,
+			TokPos:  0,
+			TokEnd:  10,
+			Limited: LimitTokens,
+		}},
+		{"modifier args runes", ParseLimits{MaxRunes: 10}, "PEPE:hue(120)", &Span{
+			Type: SpanMessage,
+			Nodes: []Node{
+				&Emote{
+					Name: "PEPE",
+					Rejected: []*RejectedModifier{
+						{Name: "hue", Reason: ModifierBadArgs, TokPos: 5, TokEnd: 8},
+					},
+					TokPos: 0,
+					TokEnd: 8,
+				},
+			},
+			TokPos:  0,
+			TokEnd:  13,
+			Limited: LimitRunes,
+		}},
+		{"modifier args tokens", ParseLimits{MaxTokens: 5}, "PEPE:hue(120)", &Span{
+			Type: SpanMessage,
+			Nodes: []Node{
+				&Emote{
+					Name: "PEPE",
+					Rejected: []*RejectedModifier{
+						{Name: "hue", Reason: ModifierBadArgs, TokPos: 5, TokEnd: 8},
+					},
+					TokPos: 0,
+					TokEnd: 8,
+				},
+			},
+			TokPos:  0,
+			TokEnd:  13,
+			Limited: LimitTokens,
+		}},
+	}
+
+	for _, c := range cases {
+		ctx := NewParserContext(ParserContextValues{
+			Emotes:         []string{"PEPE"},
+			EmoteModifiers: []string{"hue"},
+			EmoteModifierRules: []EmoteModifierRule{
+				{Name: "hue", MinArgs: 1, MaxArgs: 1},
+			},
+			Shortcodes: map[string]string{"thumbsup": "👍"},
+			Limits:     c.limits,
+		})
+		p := NewParser(ctx, NewLexer(c.input))
+		ast := p.ParseMessage()
+
+		if !reflect.DeepEqual(c.ast, ast) {
+			t.Errorf("%s: got\n%s\nexpected\n%s", c.name, spew.Sdump(ast), spew.Sdump(c.ast))
+		}
+
+		p = NewParser(ctx, lexer{})
+		p.Reset(c.input)
+		if max := c.limits.MaxRunes; max != 0 && len(p.lexer.input) > max+1 {
+			t.Errorf("%s: decoded %d runes with a limit of %d", c.name, len(p.lexer.input), max)
+		}
+		if ast := p.ParseMessage(); !reflect.DeepEqual(c.ast, ast) {
+			t.Errorf("%s: got\n%s\nexpected\n%s after Reset", c.name, spew.Sdump(ast), spew.Sdump(c.ast))
+		}
+	}
+}
+
+func TestParseLimitsByteOffset(t *testing.T) {
+	ctx := NewParserContext(ParserContextValues{Limits: ParseLimits{MaxRunes: 3}})
+	for _, input := range []string{"héllo wörld", "hello wörld", "hello world"} {
+		p := NewParser(ctx, lexer{})
+		p.ResetBytes([]byte(input))
+		msg := p.ParseMessage()
+
+		if len(p.lexer.input) != 4 {
+			t.Errorf("%q: decoded %d runes", input, len(p.lexer.input))
+		}
+		if n := p.ByteOffset(msg.TokEnd); n != len(input) {
+			t.Errorf("%q: got end offset %d expected %d", input, n, len(input))
+		}
+		if n := p.ByteOffset(3); n != len(string([]rune(input)[:3])) {
+			t.Errorf("%q: got offset %d for rune 3", input, n)
+		}
+	}
+}
+
+func TestMessageTags(t *testing.T) {
+	ctx := NewParserContext(ParserContextValues{
+		Tags: []string{"nsfw", "nsfl", "weeb"},
+	})
+
+	p := NewParser(ctx, NewLexer("nsfl ||weeb nsfl|| nsfw"))
+	tags := MessageTags(p.ParseMessage())
+
+	expected := []string{"nsfl", "weeb", "nsfw"}
+	if !reflect.DeepEqual(expected, tags) {
+		t.Errorf("got %v expected %v", tags, expected)
+	}
+}
+
+func TestEmoteModifierRules(t *testing.T) {
+	ctx := NewParserContext(ParserContextValues{
+		Emotes:         []string{"PEPE", "CuckCrab"},
+		EmoteModifiers: []string{"wide", "flip", "mirror", "hue", "speed", "rain", "snow"},
+		EmoteModifierRules: []EmoteModifierRule{
+			{Name: "hue", MinArgs: 1, MaxArgs: 1, MaxCount: 1},
+			{Name: "speed", MinArgs: 1, MaxArgs: 1},
+			{Name: "wide", MaxCount: 1},
+			{Name: "rain", Exclusive: []string{"snow"}},
+			{Name: "flip", Emotes: []string{"PEPE"}},
+		},
+		MaxEmoteModifiers: 4,
+	})
+
+	cases := []struct {
+		name  string
+		input string
+		emote *Emote
+	}{
+		{"parenthesized args", "PEPE:hue(120)", &Emote{
+			Name:         "PEPE",
+			Modifiers:    []string{"hue"},
+			ModifierArgs: [][]string{{"120"}},
+			TokPos:       0,
+			TokEnd:       13,
+		}},
+		{"hash arg", "PEPE:hue(#fff)", &Emote{
+			Name:         "PEPE",
+			Modifiers:    []string{"hue"},
+			ModifierArgs: [][]string{{"#fff"}},
+			TokPos:       0,
+			TokEnd:       14,
+		}},
+		{"colon arg", "PEPE:wide:speed:2 x", &Emote{
+			Name:         "PEPE",
+			Modifiers:    []string{"wide", "speed"},
+			ModifierArgs: [][]string{nil, {"2"}},
+			TokPos:       0,
+			TokEnd:       17,
+		}},
+		{"max count", "PEPE:wide:wide:flip", &Emote{
+			Name:      "PEPE",
+			Modifiers: []string{"wide", "flip"},
+			Rejected: []*RejectedModifier{
+				{Name: "wide", Reason: ModifierTooMany, TokPos: 10, TokEnd: 14},
+			},
+			TokPos: 0,
+			TokEnd: 19,
+		}},
+		{"allowed emotes and exclusion", "CuckCrab:flip:rain:snow", &Emote{
+			Name:      "CuckCrab",
+			Modifiers: []string{"rain"},
+			Rejected: []*RejectedModifier{
+				{Name: "flip", Reason: ModifierNotAllowed, TokPos: 9, TokEnd: 13},
+				{Name: "snow", Reason: ModifierExclusive, TokPos: 19, TokEnd: 23},
+			},
+			TokPos: 0,
+			TokEnd: 23,
+		}},
+		{"missing args", "PEPE:hue", &Emote{
+			Name: "PEPE",
+			Rejected: []*RejectedModifier{
+				{Name: "hue", Reason: ModifierBadArgs, TokPos: 5, TokEnd: 8},
+			},
+			TokPos: 0,
+			TokEnd: 8,
+		}},
+		{"unclosed args", "PEPE:hue(1 PEPE", &Emote{
+			Name: "PEPE",
+			Rejected: []*RejectedModifier{
+				{Name: "hue", Reason: ModifierBadArgs, TokPos: 5, TokEnd: 8},
+			},
+			TokPos: 0,
+			TokEnd: 8,
+		}},
+		{"modifier is not an arg", "PEPE:speed:wide", &Emote{
+			Name:      "PEPE",
+			Modifiers: []string{"wide"},
+			Rejected: []*RejectedModifier{
+				{Name: "speed", Reason: ModifierBadArgs, TokPos: 5, TokEnd: 10},
+			},
+			TokPos: 0,
+			TokEnd: 15,
+		}},
+		{"total limit", "PEPE:mirror:mirror:mirror:mirror:mirror", &Emote{
+			Name:      "PEPE",
+			Modifiers: []string{"mirror", "mirror", "mirror", "mirror"},
+			Rejected: []*RejectedModifier{
+				{Name: "mirror", Reason: ModifierLimit, TokPos: 33, TokEnd: 39},
+			},
+			TokPos: 0,
+			TokEnd: 39,
+		}},
+	}
+
+	for _, c := range cases {
+		p := NewParser(ctx, NewLexer(c.input))
+		ast := p.ParseMessage()
+
+		if len(ast.Nodes) == 0 || !reflect.DeepEqual(c.emote, ast.Nodes[0]) {
+			t.Errorf("%s: got\n%s\nexpected\n%s", c.name, spew.Sdump(ast), spew.Sdump(c.emote))
+		}
+	}
+}
+
 func TestRuneIndex(t *testing.T) {
 	v := NewRuneIndex(RunesFromStrings([]string{"g", "d", "a", "c", "f"}))
 
@@ -659,6 +1217,39 @@ func TestNickIndex(t *testing.T) {
 	}
 }
 
+func TestNickIndexReplace(t *testing.T) {
+	v := NewNickIndex(nil)
+	v.InsertWithMeta([]rune("abeous"), "mod")
+	v.InsertWithMeta([]rune("wrxst"), "bot")
+
+	v.Replace(RunesFromStrings([]string{"Abeous", "bob"}))
+
+	if it := v.Get([]rune("abeous")); it == nil || it.nick != "Abeous" || it.meta != "mod" {
+		t.Errorf("expected abeous to keep its meta, got %+v", it)
+	}
+	if it := v.Get([]rune("bob")); it == nil || it.meta != nil {
+		t.Errorf("expected bob without meta, got %+v", it)
+	}
+	if v.Contains([]rune("wrxst")) {
+		t.Error("expected wrxst to be removed")
+	}
+}
+
+func TestNickIndexInsertNoReplace(t *testing.T) {
+	v := NewNickIndex(nil)
+	v.InsertWithMeta([]rune("abeous"), "mod")
+
+	if v.InsertNoReplace([]rune("ABEOUS")) {
+		t.Error("expected abeous not to be inserted again")
+	}
+	if it := v.Get([]rune("abeous")); it == nil || it.nick != "abeous" || it.meta != "mod" {
+		t.Errorf("expected abeous to keep its meta, got %+v", it)
+	}
+	if !v.InsertNoReplace([]rune("bob")) || !v.Contains([]rune("bob")) {
+		t.Error("expected bob to be inserted")
+	}
+}
+
 func BenchmarkParse(b *testing.B) {
 	ctx := NewParserContext(ParserContextValues{
 		Emotes:         []string{"PEPE", "CuckCrab"},
@@ -676,12 +1267,17 @@ func BenchmarkParse(b *testing.B) {
 	}
 }
 
-func BenchmarkParseCorpus(b *testing.B) {
-	samples := make([]string, 300)
+func readCorpus(n int) []string {
+	samples := make([]string, n)
 	for i := 0; i < len(samples); i++ {
 		d, _ := ioutil.ReadFile(path.Join(".", "corpus", strconv.Itoa(i)))
 		samples[i] = string(d)
 	}
+	return samples
+}
+
+func BenchmarkParseCorpus(b *testing.B) {
+	samples := readCorpus(300)
 
 	ctx := NewParserContext(ParserContextValues{
 		Emotes:         []string{"PEPE", "CuckCrab"},
@@ -689,6 +1285,7 @@ func BenchmarkParseCorpus(b *testing.B) {
 		Nicks:          []string{"abeous", "jeanpierrepratt", "wrxst"},
 		Tags:           []string{"nsfw"},
 	})
+	b.ReportAllocs()
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
@@ -697,3 +1294,46 @@ func BenchmarkParseCorpus(b *testing.B) {
 		_ = ast
 	}
 }
+
+func BenchmarkParseCorpusReuse(b *testing.B) {
+	samples := readCorpus(300)
+
+	ctx := NewParserContext(ParserContextValues{
+		Emotes:         []string{"PEPE", "CuckCrab"},
+		EmoteModifiers: []string{"wide", "rustle", "spin"},
+		Nicks:          []string{"abeous", "jeanpierrepratt", "wrxst"},
+		Tags:           []string{"nsfw"},
+	})
+	p := NewReusableParser(ctx)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p.Reset(samples[i%len(samples)])
+		ast := p.ParseMessage()
+		_ = ast
+	}
+}
+
+func BenchmarkParseCorpusBytes(b *testing.B) {
+	samples := make([][]byte, 300)
+	for i, s := range readCorpus(len(samples)) {
+		samples[i] = []byte(s)
+	}
+
+	ctx := NewParserContext(ParserContextValues{
+		Emotes:         []string{"PEPE", "CuckCrab"},
+		EmoteModifiers: []string{"wide", "rustle", "spin"},
+		Nicks:          []string{"abeous", "jeanpierrepratt", "wrxst"},
+		Tags:           []string{"nsfw"},
+	})
+	p := NewReusableParser(ctx)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p.ResetBytes(samples[i%len(samples)])
+		ast := p.ParseMessage()
+		_ = ast
+	}
+}
@@ -0,0 +1,1411 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v3.5.1-go
+// source: chat.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SpanType values match parser.SpanType.
+type SpanType int32
+
+const (
+	SpanType_SPAN_TYPE_MESSAGE   SpanType = 0
+	SpanType_SPAN_TYPE_TEXT      SpanType = 1
+	SpanType_SPAN_TYPE_CODE      SpanType = 2
+	SpanType_SPAN_TYPE_GREENTEXT SpanType = 3
+	SpanType_SPAN_TYPE_SPOILER   SpanType = 4
+	SpanType_SPAN_TYPE_ME        SpanType = 5
+)
+
+// Enum value maps for SpanType.
+var (
+	SpanType_name = map[int32]string{
+		0: "SPAN_TYPE_MESSAGE",
+		1: "SPAN_TYPE_TEXT",
+		2: "SPAN_TYPE_CODE",
+		3: "SPAN_TYPE_GREENTEXT",
+		4: "SPAN_TYPE_SPOILER",
+		5: "SPAN_TYPE_ME",
+	}
+	SpanType_value = map[string]int32{
+		"SPAN_TYPE_MESSAGE":   0,
+		"SPAN_TYPE_TEXT":      1,
+		"SPAN_TYPE_CODE":      2,
+		"SPAN_TYPE_GREENTEXT": 3,
+		"SPAN_TYPE_SPOILER":   4,
+		"SPAN_TYPE_ME":        5,
+	}
+)
+
+func (x SpanType) Enum() *SpanType {
+	p := new(SpanType)
+	*p = x
+	return p
+}
+
+func (x SpanType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SpanType) Descriptor() protoreflect.EnumDescriptor {
+	return file_chat_proto_enumTypes[0].Descriptor()
+}
+
+func (SpanType) Type() protoreflect.EnumType {
+	return &file_chat_proto_enumTypes[0]
+}
+
+func (x SpanType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SpanType.Descriptor instead.
+func (SpanType) EnumDescriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{0}
+}
+
+// ModifierRejectReason values match parser.ModifierRejectReason.
+type ModifierRejectReason int32
+
+const (
+	ModifierRejectReason_MODIFIER_REJECT_REASON_BAD_ARGS    ModifierRejectReason = 0
+	ModifierRejectReason_MODIFIER_REJECT_REASON_NOT_ALLOWED ModifierRejectReason = 1
+	ModifierRejectReason_MODIFIER_REJECT_REASON_TOO_MANY    ModifierRejectReason = 2
+	ModifierRejectReason_MODIFIER_REJECT_REASON_EXCLUSIVE   ModifierRejectReason = 3
+	ModifierRejectReason_MODIFIER_REJECT_REASON_LIMIT       ModifierRejectReason = 4
+)
+
+// Enum value maps for ModifierRejectReason.
+var (
+	ModifierRejectReason_name = map[int32]string{
+		0: "MODIFIER_REJECT_REASON_BAD_ARGS",
+		1: "MODIFIER_REJECT_REASON_NOT_ALLOWED",
+		2: "MODIFIER_REJECT_REASON_TOO_MANY",
+		3: "MODIFIER_REJECT_REASON_EXCLUSIVE",
+		4: "MODIFIER_REJECT_REASON_LIMIT",
+	}
+	ModifierRejectReason_value = map[string]int32{
+		"MODIFIER_REJECT_REASON_BAD_ARGS":    0,
+		"MODIFIER_REJECT_REASON_NOT_ALLOWED": 1,
+		"MODIFIER_REJECT_REASON_TOO_MANY":    2,
+		"MODIFIER_REJECT_REASON_EXCLUSIVE":   3,
+		"MODIFIER_REJECT_REASON_LIMIT":       4,
+	}
+)
+
+func (x ModifierRejectReason) Enum() *ModifierRejectReason {
+	p := new(ModifierRejectReason)
+	*p = x
+	return p
+}
+
+func (x ModifierRejectReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ModifierRejectReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_chat_proto_enumTypes[1].Descriptor()
+}
+
+func (ModifierRejectReason) Type() protoreflect.EnumType {
+	return &file_chat_proto_enumTypes[1]
+}
+
+func (x ModifierRejectReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ModifierRejectReason.Descriptor instead.
+func (ModifierRejectReason) EnumDescriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{1}
+}
+
+// LinkClass values match parser.LinkClass.
+type LinkClass int32
+
+const (
+	LinkClass_LINK_CLASS_UNCLASSIFIED LinkClass = 0
+	LinkClass_LINK_CLASS_ALLOWED      LinkClass = 1
+	LinkClass_LINK_CLASS_WARNED       LinkClass = 2
+	LinkClass_LINK_CLASS_BLOCKED      LinkClass = 3
+	LinkClass_LINK_CLASS_IMAGE        LinkClass = 4
+	LinkClass_LINK_CLASS_VIDEO        LinkClass = 5
+	LinkClass_LINK_CLASS_INTERNAL     LinkClass = 6
+)
+
+// Enum value maps for LinkClass.
+var (
+	LinkClass_name = map[int32]string{
+		0: "LINK_CLASS_UNCLASSIFIED",
+		1: "LINK_CLASS_ALLOWED",
+		2: "LINK_CLASS_WARNED",
+		3: "LINK_CLASS_BLOCKED",
+		4: "LINK_CLASS_IMAGE",
+		5: "LINK_CLASS_VIDEO",
+		6: "LINK_CLASS_INTERNAL",
+	}
+	LinkClass_value = map[string]int32{
+		"LINK_CLASS_UNCLASSIFIED": 0,
+		"LINK_CLASS_ALLOWED":      1,
+		"LINK_CLASS_WARNED":       2,
+		"LINK_CLASS_BLOCKED":      3,
+		"LINK_CLASS_IMAGE":        4,
+		"LINK_CLASS_VIDEO":        5,
+		"LINK_CLASS_INTERNAL":     6,
+	}
+)
+
+func (x LinkClass) Enum() *LinkClass {
+	p := new(LinkClass)
+	*p = x
+	return p
+}
+
+func (x LinkClass) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LinkClass) Descriptor() protoreflect.EnumDescriptor {
+	return file_chat_proto_enumTypes[2].Descriptor()
+}
+
+func (LinkClass) Type() protoreflect.EnumType {
+	return &file_chat_proto_enumTypes[2]
+}
+
+func (x LinkClass) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LinkClass.Descriptor instead.
+func (LinkClass) EnumDescriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{2}
+}
+
+// Node holds one AST node. New node types are added as new fields of the
+// oneof.
+type Node struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Node:
+	//
+	//	*Node_Span
+	//	*Node_Emote
+	//	*Node_Nick
+	//	*Node_Tag
+	//	*Node_Link
+	//	*Node_Emoji
+	//	*Node_ChannelRef
+	Node          isNode_Node `protobuf_oneof:"node"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	mi := &file_chat_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Node) GetNode() isNode_Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *Node) GetSpan() *Span {
+	if x != nil {
+		if x, ok := x.Node.(*Node_Span); ok {
+			return x.Span
+		}
+	}
+	return nil
+}
+
+func (x *Node) GetEmote() *Emote {
+	if x != nil {
+		if x, ok := x.Node.(*Node_Emote); ok {
+			return x.Emote
+		}
+	}
+	return nil
+}
+
+func (x *Node) GetNick() *Nick {
+	if x != nil {
+		if x, ok := x.Node.(*Node_Nick); ok {
+			return x.Nick
+		}
+	}
+	return nil
+}
+
+func (x *Node) GetTag() *Tag {
+	if x != nil {
+		if x, ok := x.Node.(*Node_Tag); ok {
+			return x.Tag
+		}
+	}
+	return nil
+}
+
+func (x *Node) GetLink() *Link {
+	if x != nil {
+		if x, ok := x.Node.(*Node_Link); ok {
+			return x.Link
+		}
+	}
+	return nil
+}
+
+func (x *Node) GetEmoji() *Emoji {
+	if x != nil {
+		if x, ok := x.Node.(*Node_Emoji); ok {
+			return x.Emoji
+		}
+	}
+	return nil
+}
+
+func (x *Node) GetChannelRef() *ChannelRef {
+	if x != nil {
+		if x, ok := x.Node.(*Node_ChannelRef); ok {
+			return x.ChannelRef
+		}
+	}
+	return nil
+}
+
+type isNode_Node interface {
+	isNode_Node()
+}
+
+type Node_Span struct {
+	Span *Span `protobuf:"bytes,1,opt,name=span,proto3,oneof"`
+}
+
+type Node_Emote struct {
+	Emote *Emote `protobuf:"bytes,2,opt,name=emote,proto3,oneof"`
+}
+
+type Node_Nick struct {
+	Nick *Nick `protobuf:"bytes,3,opt,name=nick,proto3,oneof"`
+}
+
+type Node_Tag struct {
+	Tag *Tag `protobuf:"bytes,4,opt,name=tag,proto3,oneof"`
+}
+
+type Node_Link struct {
+	Link *Link `protobuf:"bytes,5,opt,name=link,proto3,oneof"`
+}
+
+type Node_Emoji struct {
+	Emoji *Emoji `protobuf:"bytes,6,opt,name=emoji,proto3,oneof"`
+}
+
+type Node_ChannelRef struct {
+	ChannelRef *ChannelRef `protobuf:"bytes,7,opt,name=channel_ref,json=channelRef,proto3,oneof"`
+}
+
+func (*Node_Span) isNode_Node() {}
+
+func (*Node_Emote) isNode_Node() {}
+
+func (*Node_Nick) isNode_Node() {}
+
+func (*Node_Tag) isNode_Node() {}
+
+func (*Node_Link) isNode_Node() {}
+
+func (*Node_Emoji) isNode_Node() {}
+
+func (*Node_ChannelRef) isNode_Node() {}
+
+type Span struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  SpanType               `protobuf:"varint,1,opt,name=type,proto3,enum=chatparser.SpanType" json:"type,omitempty"`
+	Nodes []*Node                `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	Pos   int32                  `protobuf:"varint,3,opt,name=pos,proto3" json:"pos,omitempty"`
+	End   int32                  `protobuf:"varint,4,opt,name=end,proto3" json:"end,omitempty"`
+	// limited holds the parser.LimitFlag bits of the message.
+	Limited       uint32 `protobuf:"varint,5,opt,name=limited,proto3" json:"limited,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Span) Reset() {
+	*x = Span{}
+	mi := &file_chat_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Span) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Span) ProtoMessage() {}
+
+func (x *Span) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Span.ProtoReflect.Descriptor instead.
+func (*Span) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Span) GetType() SpanType {
+	if x != nil {
+		return x.Type
+	}
+	return SpanType_SPAN_TYPE_MESSAGE
+}
+
+func (x *Span) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *Span) GetPos() int32 {
+	if x != nil {
+		return x.Pos
+	}
+	return 0
+}
+
+func (x *Span) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *Span) GetLimited() uint32 {
+	if x != nil {
+		return x.Limited
+	}
+	return 0
+}
+
+type StringList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []string               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StringList) Reset() {
+	*x = StringList{}
+	mi := &file_chat_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StringList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StringList) ProtoMessage() {}
+
+func (x *StringList) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StringList.ProtoReflect.Descriptor instead.
+func (*StringList) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StringList) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type ModifierArgs struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// args is unset when the modifier was used without arguments.
+	Args          *StringList `protobuf:"bytes,1,opt,name=args,proto3" json:"args,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModifierArgs) Reset() {
+	*x = ModifierArgs{}
+	mi := &file_chat_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModifierArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModifierArgs) ProtoMessage() {}
+
+func (x *ModifierArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModifierArgs.ProtoReflect.Descriptor instead.
+func (*ModifierArgs) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ModifierArgs) GetArgs() *StringList {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+type RejectedModifier struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Args          *StringList            `protobuf:"bytes,2,opt,name=args,proto3" json:"args,omitempty"`
+	Reason        ModifierRejectReason   `protobuf:"varint,3,opt,name=reason,proto3,enum=chatparser.ModifierRejectReason" json:"reason,omitempty"`
+	Pos           int32                  `protobuf:"varint,4,opt,name=pos,proto3" json:"pos,omitempty"`
+	End           int32                  `protobuf:"varint,5,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RejectedModifier) Reset() {
+	*x = RejectedModifier{}
+	mi := &file_chat_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RejectedModifier) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectedModifier) ProtoMessage() {}
+
+func (x *RejectedModifier) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectedModifier.ProtoReflect.Descriptor instead.
+func (*RejectedModifier) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RejectedModifier) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RejectedModifier) GetArgs() *StringList {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *RejectedModifier) GetReason() ModifierRejectReason {
+	if x != nil {
+		return x.Reason
+	}
+	return ModifierRejectReason_MODIFIER_REJECT_REASON_BAD_ARGS
+}
+
+func (x *RejectedModifier) GetPos() int32 {
+	if x != nil {
+		return x.Pos
+	}
+	return 0
+}
+
+func (x *RejectedModifier) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+type Emote struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Name      string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Modifiers []string               `protobuf:"bytes,2,rep,name=modifiers,proto3" json:"modifiers,omitempty"`
+	// modifier_args is empty unless at least one modifier has arguments, in
+	// which case it has an entry for each modifier.
+	ModifierArgs  []*ModifierArgs     `protobuf:"bytes,3,rep,name=modifier_args,json=modifierArgs,proto3" json:"modifier_args,omitempty"`
+	Rejected      []*RejectedModifier `protobuf:"bytes,4,rep,name=rejected,proto3" json:"rejected,omitempty"`
+	Pos           int32               `protobuf:"varint,5,opt,name=pos,proto3" json:"pos,omitempty"`
+	End           int32               `protobuf:"varint,6,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Emote) Reset() {
+	*x = Emote{}
+	mi := &file_chat_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Emote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Emote) ProtoMessage() {}
+
+func (x *Emote) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Emote.ProtoReflect.Descriptor instead.
+func (*Emote) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Emote) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Emote) GetModifiers() []string {
+	if x != nil {
+		return x.Modifiers
+	}
+	return nil
+}
+
+func (x *Emote) GetModifierArgs() []*ModifierArgs {
+	if x != nil {
+		return x.ModifierArgs
+	}
+	return nil
+}
+
+func (x *Emote) GetRejected() []*RejectedModifier {
+	if x != nil {
+		return x.Rejected
+	}
+	return nil
+}
+
+func (x *Emote) GetPos() int32 {
+	if x != nil {
+		return x.Pos
+	}
+	return 0
+}
+
+func (x *Emote) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+type Nick struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nick          string                 `protobuf:"bytes,1,opt,name=nick,proto3" json:"nick,omitempty"`
+	Meta          *structpb.Value        `protobuf:"bytes,2,opt,name=meta,proto3" json:"meta,omitempty"`
+	Pos           int32                  `protobuf:"varint,3,opt,name=pos,proto3" json:"pos,omitempty"`
+	End           int32                  `protobuf:"varint,4,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Nick) Reset() {
+	*x = Nick{}
+	mi := &file_chat_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Nick) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Nick) ProtoMessage() {}
+
+func (x *Nick) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Nick.ProtoReflect.Descriptor instead.
+func (*Nick) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Nick) GetNick() string {
+	if x != nil {
+		return x.Nick
+	}
+	return ""
+}
+
+func (x *Nick) GetMeta() *structpb.Value {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+func (x *Nick) GetPos() int32 {
+	if x != nil {
+		return x.Pos
+	}
+	return 0
+}
+
+func (x *Nick) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+type Tag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Pos           int32                  `protobuf:"varint,2,opt,name=pos,proto3" json:"pos,omitempty"`
+	End           int32                  `protobuf:"varint,3,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tag) Reset() {
+	*x = Tag{}
+	mi := &file_chat_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tag) ProtoMessage() {}
+
+func (x *Tag) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tag.ProtoReflect.Descriptor instead.
+func (*Tag) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Tag) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Tag) GetPos() int32 {
+	if x != nil {
+		return x.Pos
+	}
+	return 0
+}
+
+func (x *Tag) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+type Link struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Class         LinkClass              `protobuf:"varint,2,opt,name=class,proto3,enum=chatparser.LinkClass" json:"class,omitempty"`
+	Tags          []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Pos           int32                  `protobuf:"varint,4,opt,name=pos,proto3" json:"pos,omitempty"`
+	End           int32                  `protobuf:"varint,5,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Link) Reset() {
+	*x = Link{}
+	mi := &file_chat_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Link) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Link) ProtoMessage() {}
+
+func (x *Link) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Link.ProtoReflect.Descriptor instead.
+func (*Link) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Link) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Link) GetClass() LinkClass {
+	if x != nil {
+		return x.Class
+	}
+	return LinkClass_LINK_CLASS_UNCLASSIFIED
+}
+
+func (x *Link) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Link) GetPos() int32 {
+	if x != nil {
+		return x.Pos
+	}
+	return 0
+}
+
+func (x *Link) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+type Emoji struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Codepoints    []int32                `protobuf:"varint,1,rep,packed,name=codepoints,proto3" json:"codepoints,omitempty"`
+	Pos           int32                  `protobuf:"varint,2,opt,name=pos,proto3" json:"pos,omitempty"`
+	End           int32                  `protobuf:"varint,3,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Emoji) Reset() {
+	*x = Emoji{}
+	mi := &file_chat_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Emoji) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Emoji) ProtoMessage() {}
+
+func (x *Emoji) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Emoji.ProtoReflect.Descriptor instead.
+func (*Emoji) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Emoji) GetCodepoints() []int32 {
+	if x != nil {
+		return x.Codepoints
+	}
+	return nil
+}
+
+func (x *Emoji) GetPos() int32 {
+	if x != nil {
+		return x.Pos
+	}
+	return 0
+}
+
+func (x *Emoji) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+type ChannelRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Channel       string                 `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	DisplayName   string                 `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Live          bool                   `protobuf:"varint,3,opt,name=live,proto3" json:"live,omitempty"`
+	Pos           int32                  `protobuf:"varint,4,opt,name=pos,proto3" json:"pos,omitempty"`
+	End           int32                  `protobuf:"varint,5,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChannelRef) Reset() {
+	*x = ChannelRef{}
+	mi := &file_chat_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChannelRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelRef) ProtoMessage() {}
+
+func (x *ChannelRef) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelRef.ProtoReflect.Descriptor instead.
+func (*ChannelRef) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ChannelRef) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *ChannelRef) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *ChannelRef) GetLive() bool {
+	if x != nil {
+		return x.Live
+	}
+	return false
+}
+
+func (x *ChannelRef) GetPos() int32 {
+	if x != nil {
+		return x.Pos
+	}
+	return 0
+}
+
+func (x *ChannelRef) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+type ParseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Input         string                 `protobuf:"bytes,1,opt,name=input,proto3" json:"input,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParseRequest) Reset() {
+	*x = ParseRequest{}
+	mi := &file_chat_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseRequest) ProtoMessage() {}
+
+func (x *ParseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseRequest.ProtoReflect.Descriptor instead.
+func (*ParseRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ParseRequest) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+type ParseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       *Span                  `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParseResponse) Reset() {
+	*x = ParseResponse{}
+	mi := &file_chat_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseResponse) ProtoMessage() {}
+
+func (x *ParseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseResponse.ProtoReflect.Descriptor instead.
+func (*ParseResponse) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ParseResponse) GetMessage() *Span {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+type ParseBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Inputs        []string               `protobuf:"bytes,1,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParseBatchRequest) Reset() {
+	*x = ParseBatchRequest{}
+	mi := &file_chat_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseBatchRequest) ProtoMessage() {}
+
+func (x *ParseBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseBatchRequest.ProtoReflect.Descriptor instead.
+func (*ParseBatchRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ParseBatchRequest) GetInputs() []string {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+type ParseBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*Span                `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParseBatchResponse) Reset() {
+	*x = ParseBatchResponse{}
+	mi := &file_chat_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseBatchResponse) ProtoMessage() {}
+
+func (x *ParseBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseBatchResponse.ProtoReflect.Descriptor instead.
+func (*ParseBatchResponse) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ParseBatchResponse) GetMessages() []*Span {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+var File_chat_proto protoreflect.FileDescriptor
+
+const file_chat_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"chat.proto\x12\n" +
+	"chatparser\x1a\x1cgoogle/protobuf/struct.proto\"\xbc\x02\n" +
+	"\x04Node\x12&\n" +
+	"\x04span\x18\x01 \x01(\v2\x10.chatparser.SpanH\x00R\x04span\x12)\n" +
+	"\x05emote\x18\x02 \x01(\v2\x11.chatparser.EmoteH\x00R\x05emote\x12&\n" +
+	"\x04nick\x18\x03 \x01(\v2\x10.chatparser.NickH\x00R\x04nick\x12#\n" +
+	"\x03tag\x18\x04 \x01(\v2\x0f.chatparser.TagH\x00R\x03tag\x12&\n" +
+	"\x04link\x18\x05 \x01(\v2\x10.chatparser.LinkH\x00R\x04link\x12)\n" +
+	"\x05emoji\x18\x06 \x01(\v2\x11.chatparser.EmojiH\x00R\x05emoji\x129\n" +
+	"\vchannel_ref\x18\a \x01(\v2\x16.chatparser.ChannelRefH\x00R\n" +
+	"channelRefB\x06\n" +
+	"\x04node\"\x96\x01\n" +
+	"\x04Span\x12(\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x14.chatparser.SpanTypeR\x04type\x12&\n" +
+	"\x05nodes\x18\x02 \x03(\v2\x10.chatparser.NodeR\x05nodes\x12\x10\n" +
+	"\x03pos\x18\x03 \x01(\x05R\x03pos\x12\x10\n" +
+	"\x03end\x18\x04 \x01(\x05R\x03end\x12\x18\n" +
+	"\alimited\x18\x05 \x01(\rR\alimited\"$\n" +
+	"\n" +
+	"StringList\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\":\n" +
+	"\fModifierArgs\x12*\n" +
+	"\x04args\x18\x01 \x01(\v2\x16.chatparser.StringListR\x04args\"\xb0\x01\n" +
+	"\x10RejectedModifier\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12*\n" +
+	"\x04args\x18\x02 \x01(\v2\x16.chatparser.StringListR\x04args\x128\n" +
+	"\x06reason\x18\x03 \x01(\x0e2 .chatparser.ModifierRejectReasonR\x06reason\x12\x10\n" +
+	"\x03pos\x18\x04 \x01(\x05R\x03pos\x12\x10\n" +
+	"\x03end\x18\x05 \x01(\x05R\x03end\"\xd6\x01\n" +
+	"\x05Emote\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
+	"\tmodifiers\x18\x02 \x03(\tR\tmodifiers\x12=\n" +
+	"\rmodifier_args\x18\x03 \x03(\v2\x18.chatparser.ModifierArgsR\fmodifierArgs\x128\n" +
+	"\brejected\x18\x04 \x03(\v2\x1c.chatparser.RejectedModifierR\brejected\x12\x10\n" +
+	"\x03pos\x18\x05 \x01(\x05R\x03pos\x12\x10\n" +
+	"\x03end\x18\x06 \x01(\x05R\x03end\"j\n" +
+	"\x04Nick\x12\x12\n" +
+	"\x04nick\x18\x01 \x01(\tR\x04nick\x12*\n" +
+	"\x04meta\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x04meta\x12\x10\n" +
+	"\x03pos\x18\x03 \x01(\x05R\x03pos\x12\x10\n" +
+	"\x03end\x18\x04 \x01(\x05R\x03end\"=\n" +
+	"\x03Tag\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03pos\x18\x02 \x01(\x05R\x03pos\x12\x10\n" +
+	"\x03end\x18\x03 \x01(\x05R\x03end\"}\n" +
+	"\x04Link\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12+\n" +
+	"\x05class\x18\x02 \x01(\x0e2\x15.chatparser.LinkClassR\x05class\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12\x10\n" +
+	"\x03pos\x18\x04 \x01(\x05R\x03pos\x12\x10\n" +
+	"\x03end\x18\x05 \x01(\x05R\x03end\"K\n" +
+	"\x05Emoji\x12\x1e\n" +
+	"\n" +
+	"codepoints\x18\x01 \x03(\x05R\n" +
+	"codepoints\x12\x10\n" +
+	"\x03pos\x18\x02 \x01(\x05R\x03pos\x12\x10\n" +
+	"\x03end\x18\x03 \x01(\x05R\x03end\"\x81\x01\n" +
+	"\n" +
+	"ChannelRef\x12\x18\n" +
+	"\achannel\x18\x01 \x01(\tR\achannel\x12!\n" +
+	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\x12\x12\n" +
+	"\x04live\x18\x03 \x01(\bR\x04live\x12\x10\n" +
+	"\x03pos\x18\x04 \x01(\x05R\x03pos\x12\x10\n" +
+	"\x03end\x18\x05 \x01(\x05R\x03end\"$\n" +
+	"\fParseRequest\x12\x14\n" +
+	"\x05input\x18\x01 \x01(\tR\x05input\";\n" +
+	"\rParseResponse\x12*\n" +
+	"\amessage\x18\x01 \x01(\v2\x10.chatparser.SpanR\amessage\"+\n" +
+	"\x11ParseBatchRequest\x12\x16\n" +
+	"\x06inputs\x18\x01 \x03(\tR\x06inputs\"B\n" +
+	"\x12ParseBatchResponse\x12,\n" +
+	"\bmessages\x18\x01 \x03(\v2\x10.chatparser.SpanR\bmessages*\x8b\x01\n" +
+	"\bSpanType\x12\x15\n" +
+	"\x11SPAN_TYPE_MESSAGE\x10\x00\x12\x12\n" +
+	"\x0eSPAN_TYPE_TEXT\x10\x01\x12\x12\n" +
+	"\x0eSPAN_TYPE_CODE\x10\x02\x12\x17\n" +
+	"\x13SPAN_TYPE_GREENTEXT\x10\x03\x12\x15\n" +
+	"\x11SPAN_TYPE_SPOILER\x10\x04\x12\x10\n" +
+	"\fSPAN_TYPE_ME\x10\x05*\xd0\x01\n" +
+	"\x14ModifierRejectReason\x12#\n" +
+	"\x1fMODIFIER_REJECT_REASON_BAD_ARGS\x10\x00\x12&\n" +
+	"\"MODIFIER_REJECT_REASON_NOT_ALLOWED\x10\x01\x12#\n" +
+	"\x1fMODIFIER_REJECT_REASON_TOO_MANY\x10\x02\x12$\n" +
+	" MODIFIER_REJECT_REASON_EXCLUSIVE\x10\x03\x12 \n" +
+	"\x1cMODIFIER_REJECT_REASON_LIMIT\x10\x04*\xb4\x01\n" +
+	"\tLinkClass\x12\x1b\n" +
+	"\x17LINK_CLASS_UNCLASSIFIED\x10\x00\x12\x16\n" +
+	"\x12LINK_CLASS_ALLOWED\x10\x01\x12\x15\n" +
+	"\x11LINK_CLASS_WARNED\x10\x02\x12\x16\n" +
+	"\x12LINK_CLASS_BLOCKED\x10\x03\x12\x14\n" +
+	"\x10LINK_CLASS_IMAGE\x10\x04\x12\x14\n" +
+	"\x10LINK_CLASS_VIDEO\x10\x05\x12\x17\n" +
+	"\x13LINK_CLASS_INTERNAL\x10\x062\x97\x01\n" +
+	"\n" +
+	"ChatParser\x12<\n" +
+	"\x05Parse\x12\x18.chatparser.ParseRequest\x1a\x19.chatparser.ParseResponse\x12K\n" +
+	"\n" +
+	"ParseBatch\x12\x1d.chatparser.ParseBatchRequest\x1a\x1e.chatparser.ParseBatchResponseB$Z\"github.com/MemeLabs/chat-parser/pbb\x06proto3"
+
+var (
+	file_chat_proto_rawDescOnce sync.Once
+	file_chat_proto_rawDescData []byte
+)
+
+func file_chat_proto_rawDescGZIP() []byte {
+	file_chat_proto_rawDescOnce.Do(func() {
+		file_chat_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_chat_proto_rawDesc), len(file_chat_proto_rawDesc)))
+	})
+	return file_chat_proto_rawDescData
+}
+
+var file_chat_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_chat_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_chat_proto_goTypes = []any{
+	(SpanType)(0),              // 0: chatparser.SpanType
+	(ModifierRejectReason)(0),  // 1: chatparser.ModifierRejectReason
+	(LinkClass)(0),             // 2: chatparser.LinkClass
+	(*Node)(nil),               // 3: chatparser.Node
+	(*Span)(nil),               // 4: chatparser.Span
+	(*StringList)(nil),         // 5: chatparser.StringList
+	(*ModifierArgs)(nil),       // 6: chatparser.ModifierArgs
+	(*RejectedModifier)(nil),   // 7: chatparser.RejectedModifier
+	(*Emote)(nil),              // 8: chatparser.Emote
+	(*Nick)(nil),               // 9: chatparser.Nick
+	(*Tag)(nil),                // 10: chatparser.Tag
+	(*Link)(nil),               // 11: chatparser.Link
+	(*Emoji)(nil),              // 12: chatparser.Emoji
+	(*ChannelRef)(nil),         // 13: chatparser.ChannelRef
+	(*ParseRequest)(nil),       // 14: chatparser.ParseRequest
+	(*ParseResponse)(nil),      // 15: chatparser.ParseResponse
+	(*ParseBatchRequest)(nil),  // 16: chatparser.ParseBatchRequest
+	(*ParseBatchResponse)(nil), // 17: chatparser.ParseBatchResponse
+	(*structpb.Value)(nil),     // 18: google.protobuf.Value
+}
+var file_chat_proto_depIdxs = []int32{
+	4,  // 0: chatparser.Node.span:type_name -> chatparser.Span
+	8,  // 1: chatparser.Node.emote:type_name -> chatparser.Emote
+	9,  // 2: chatparser.Node.nick:type_name -> chatparser.Nick
+	10, // 3: chatparser.Node.tag:type_name -> chatparser.Tag
+	11, // 4: chatparser.Node.link:type_name -> chatparser.Link
+	12, // 5: chatparser.Node.emoji:type_name -> chatparser.Emoji
+	13, // 6: chatparser.Node.channel_ref:type_name -> chatparser.ChannelRef
+	0,  // 7: chatparser.Span.type:type_name -> chatparser.SpanType
+	3,  // 8: chatparser.Span.nodes:type_name -> chatparser.Node
+	5,  // 9: chatparser.ModifierArgs.args:type_name -> chatparser.StringList
+	5,  // 10: chatparser.RejectedModifier.args:type_name -> chatparser.StringList
+	1,  // 11: chatparser.RejectedModifier.reason:type_name -> chatparser.ModifierRejectReason
+	6,  // 12: chatparser.Emote.modifier_args:type_name -> chatparser.ModifierArgs
+	7,  // 13: chatparser.Emote.rejected:type_name -> chatparser.RejectedModifier
+	18, // 14: chatparser.Nick.meta:type_name -> google.protobuf.Value
+	2,  // 15: chatparser.Link.class:type_name -> chatparser.LinkClass
+	4,  // 16: chatparser.ParseResponse.message:type_name -> chatparser.Span
+	4,  // 17: chatparser.ParseBatchResponse.messages:type_name -> chatparser.Span
+	14, // 18: chatparser.ChatParser.Parse:input_type -> chatparser.ParseRequest
+	16, // 19: chatparser.ChatParser.ParseBatch:input_type -> chatparser.ParseBatchRequest
+	15, // 20: chatparser.ChatParser.Parse:output_type -> chatparser.ParseResponse
+	17, // 21: chatparser.ChatParser.ParseBatch:output_type -> chatparser.ParseBatchResponse
+	20, // [20:22] is the sub-list for method output_type
+	18, // [18:20] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_chat_proto_init() }
+func file_chat_proto_init() {
+	if File_chat_proto != nil {
+		return
+	}
+	file_chat_proto_msgTypes[0].OneofWrappers = []any{
+		(*Node_Span)(nil),
+		(*Node_Emote)(nil),
+		(*Node_Nick)(nil),
+		(*Node_Tag)(nil),
+		(*Node_Link)(nil),
+		(*Node_Emoji)(nil),
+		(*Node_ChannelRef)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_chat_proto_rawDesc), len(file_chat_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_chat_proto_goTypes,
+		DependencyIndexes: file_chat_proto_depIdxs,
+		EnumInfos:         file_chat_proto_enumTypes,
+		MessageInfos:      file_chat_proto_msgTypes,
+	}.Build()
+	File_chat_proto = out.File
+	file_chat_proto_goTypes = nil
+	file_chat_proto_depIdxs = nil
+}
@@ -0,0 +1,165 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v3.5.1-go
+// source: chat.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ChatParser_Parse_FullMethodName      = "/chatparser.ChatParser/Parse"
+	ChatParser_ParseBatch_FullMethodName = "/chatparser.ChatParser/ParseBatch"
+)
+
+// ChatParserClient is the client API for ChatParser service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ChatParser parses messages with the server's parser context.
+type ChatParserClient interface {
+	Parse(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ParseResponse, error)
+	// ParseBatch parses many messages concurrently and returns them in order.
+	ParseBatch(ctx context.Context, in *ParseBatchRequest, opts ...grpc.CallOption) (*ParseBatchResponse, error)
+}
+
+type chatParserClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChatParserClient(cc grpc.ClientConnInterface) ChatParserClient {
+	return &chatParserClient{cc}
+}
+
+func (c *chatParserClient) Parse(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ParseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ParseResponse)
+	err := c.cc.Invoke(ctx, ChatParser_Parse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatParserClient) ParseBatch(ctx context.Context, in *ParseBatchRequest, opts ...grpc.CallOption) (*ParseBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ParseBatchResponse)
+	err := c.cc.Invoke(ctx, ChatParser_ParseBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChatParserServer is the server API for ChatParser service.
+// All implementations must embed UnimplementedChatParserServer
+// for forward compatibility.
+//
+// ChatParser parses messages with the server's parser context.
+type ChatParserServer interface {
+	Parse(context.Context, *ParseRequest) (*ParseResponse, error)
+	// ParseBatch parses many messages concurrently and returns them in order.
+	ParseBatch(context.Context, *ParseBatchRequest) (*ParseBatchResponse, error)
+	mustEmbedUnimplementedChatParserServer()
+}
+
+// UnimplementedChatParserServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedChatParserServer struct{}
+
+func (UnimplementedChatParserServer) Parse(context.Context, *ParseRequest) (*ParseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Parse not implemented")
+}
+func (UnimplementedChatParserServer) ParseBatch(context.Context, *ParseBatchRequest) (*ParseBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ParseBatch not implemented")
+}
+func (UnimplementedChatParserServer) mustEmbedUnimplementedChatParserServer() {}
+func (UnimplementedChatParserServer) testEmbeddedByValue()                    {}
+
+// UnsafeChatParserServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChatParserServer will
+// result in compilation errors.
+type UnsafeChatParserServer interface {
+	mustEmbedUnimplementedChatParserServer()
+}
+
+func RegisterChatParserServer(s grpc.ServiceRegistrar, srv ChatParserServer) {
+	// If the following call pancis, it indicates UnimplementedChatParserServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ChatParser_ServiceDesc, srv)
+}
+
+func _ChatParser_Parse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatParserServer).Parse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatParser_Parse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatParserServer).Parse(ctx, req.(*ParseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatParser_ParseBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParseBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatParserServer).ParseBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatParser_ParseBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatParserServer).ParseBatch(ctx, req.(*ParseBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ChatParser_ServiceDesc is the grpc.ServiceDesc for ChatParser service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChatParser_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "chatparser.ChatParser",
+	HandlerType: (*ChatParserServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Parse",
+			Handler:    _ChatParser_Parse_Handler,
+		},
+		{
+			MethodName: "ParseBatch",
+			Handler:    _ChatParser_ParseBatch_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "chat.proto",
+}
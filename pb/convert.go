@@ -0,0 +1,219 @@
+package pb
+
+import (
+	"fmt"
+
+	parser "github.com/MemeLabs/chat-parser"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// SpanToProto converts a parsed message or span to its protobuf form. It
+// fails when a nick's Meta cannot be represented as a google.protobuf.Value.
+func SpanToProto(s *parser.Span) (*Span, error) {
+	p := &Span{
+		Type:    SpanType(s.Type),
+		Pos:     int32(s.TokPos),
+		End:     int32(s.TokEnd),
+		Limited: uint32(s.Limited),
+	}
+	for _, n := range s.Nodes {
+		pn, err := NodeToProto(n)
+		if err != nil {
+			return nil, err
+		}
+		p.Nodes = append(p.Nodes, pn)
+	}
+	return p, nil
+}
+
+// SpanFromProto converts a protobuf span back to the parser's AST. Nick Meta
+// values come back as the types produced by structpb.Value.AsInterface.
+func SpanFromProto(p *Span) *parser.Span {
+	s := &parser.Span{
+		Type:    parser.SpanType(p.Type),
+		TokPos:  int(p.Pos),
+		TokEnd:  int(p.End),
+		Limited: parser.LimitFlag(p.Limited),
+	}
+	for _, n := range p.Nodes {
+		if sn := NodeFromProto(n); sn != nil {
+			s.Nodes = append(s.Nodes, sn)
+		}
+	}
+	return s
+}
+
+func NodeToProto(n parser.Node) (*Node, error) {
+	switch n := n.(type) {
+	case *parser.Span:
+		s, err := SpanToProto(n)
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Node: &Node_Span{Span: s}}, nil
+	case *parser.Emote:
+		return &Node{Node: &Node_Emote{Emote: emoteToProto(n)}}, nil
+	case *parser.Nick:
+		p := &Nick{
+			Nick: n.Nick,
+			Pos:  int32(n.TokPos),
+			End:  int32(n.TokEnd),
+		}
+		if n.Meta != nil {
+			m, err := structpb.NewValue(n.Meta)
+			if err != nil {
+				return nil, fmt.Errorf("nick %s meta: %w", n.Nick, err)
+			}
+			p.Meta = m
+		}
+		return &Node{Node: &Node_Nick{Nick: p}}, nil
+	case *parser.Tag:
+		return &Node{Node: &Node_Tag{Tag: &Tag{
+			Name: n.Name,
+			Pos:  int32(n.TokPos),
+			End:  int32(n.TokEnd),
+		}}}, nil
+	case *parser.Link:
+		return &Node{Node: &Node_Link{Link: &Link{
+			Url:   n.URL,
+			Class: LinkClass(n.Class),
+			Tags:  n.Tags,
+			Pos:   int32(n.TokPos),
+			End:   int32(n.TokEnd),
+		}}}, nil
+	case *parser.Emoji:
+		p := &Emoji{
+			Codepoints: make([]int32, len(n.Codepoints)),
+			Pos:        int32(n.TokPos),
+			End:        int32(n.TokEnd),
+		}
+		for i, r := range n.Codepoints {
+			p.Codepoints[i] = int32(r)
+		}
+		return &Node{Node: &Node_Emoji{Emoji: p}}, nil
+	case *parser.ChannelRef:
+		return &Node{Node: &Node_ChannelRef{ChannelRef: &ChannelRef{
+			Channel:     n.Channel,
+			DisplayName: n.DisplayName,
+			Live:        n.Live,
+			Pos:         int32(n.TokPos),
+			End:         int32(n.TokEnd),
+		}}}, nil
+	}
+	return nil, fmt.Errorf("unsupported node type %T", n)
+}
+
+// NodeFromProto converts a protobuf node back to the parser's AST. It returns
+// nil for nodes with no value set, e.g. node types unknown to this version.
+func NodeFromProto(p *Node) parser.Node {
+	switch n := p.Node.(type) {
+	case *Node_Span:
+		return SpanFromProto(n.Span)
+	case *Node_Emote:
+		return emoteFromProto(n.Emote)
+	case *Node_Nick:
+		s := &parser.Nick{
+			Nick:   n.Nick.Nick,
+			TokPos: int(n.Nick.Pos),
+			TokEnd: int(n.Nick.End),
+		}
+		if n.Nick.Meta != nil {
+			s.Meta = n.Nick.Meta.AsInterface()
+		}
+		return s
+	case *Node_Tag:
+		return &parser.Tag{
+			Name:   n.Tag.Name,
+			TokPos: int(n.Tag.Pos),
+			TokEnd: int(n.Tag.End),
+		}
+	case *Node_Link:
+		return &parser.Link{
+			URL:    n.Link.Url,
+			Class:  parser.LinkClass(n.Link.Class),
+			Tags:   n.Link.Tags,
+			TokPos: int(n.Link.Pos),
+			TokEnd: int(n.Link.End),
+		}
+	case *Node_Emoji:
+		s := &parser.Emoji{
+			Codepoints: make([]rune, len(n.Emoji.Codepoints)),
+			TokPos:     int(n.Emoji.Pos),
+			TokEnd:     int(n.Emoji.End),
+		}
+		for i, r := range n.Emoji.Codepoints {
+			s.Codepoints[i] = rune(r)
+		}
+		return s
+	case *Node_ChannelRef:
+		return &parser.ChannelRef{
+			Channel:     n.ChannelRef.Channel,
+			DisplayName: n.ChannelRef.DisplayName,
+			Live:        n.ChannelRef.Live,
+			TokPos:      int(n.ChannelRef.Pos),
+			TokEnd:      int(n.ChannelRef.End),
+		}
+	}
+	return nil
+}
+
+func stringListToProto(v []string) *StringList {
+	if v == nil {
+		return nil
+	}
+	return &StringList{Values: v}
+}
+
+func stringListFromProto(p *StringList) []string {
+	if p == nil {
+		return nil
+	}
+	if p.Values == nil {
+		return []string{}
+	}
+	return p.Values
+}
+
+func emoteToProto(e *parser.Emote) *Emote {
+	p := &Emote{
+		Name:      e.Name,
+		Modifiers: e.Modifiers,
+		Pos:       int32(e.TokPos),
+		End:       int32(e.TokEnd),
+	}
+	for _, args := range e.ModifierArgs {
+		p.ModifierArgs = append(p.ModifierArgs, &ModifierArgs{Args: stringListToProto(args)})
+	}
+	for _, r := range e.Rejected {
+		p.Rejected = append(p.Rejected, &RejectedModifier{
+			Name:   r.Name,
+			Args:   stringListToProto(r.Args),
+			Reason: ModifierRejectReason(r.Reason),
+			Pos:    int32(r.TokPos),
+			End:    int32(r.TokEnd),
+		})
+	}
+	return p
+}
+
+func emoteFromProto(p *Emote) *parser.Emote {
+	e := &parser.Emote{
+		Name:      p.Name,
+		Modifiers: p.Modifiers,
+		TokPos:    int(p.Pos),
+		TokEnd:    int(p.End),
+	}
+	for _, args := range p.ModifierArgs {
+		e.ModifierArgs = append(e.ModifierArgs, stringListFromProto(args.Args))
+	}
+	for _, r := range p.Rejected {
+		e.Rejected = append(e.Rejected, &parser.RejectedModifier{
+			Name:   r.Name,
+			Args:   stringListFromProto(r.Args),
+			Reason: parser.ModifierRejectReason(r.Reason),
+			TokPos: int(r.Pos),
+			TokEnd: int(r.End),
+		})
+	}
+	return e
+}
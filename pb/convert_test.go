@@ -0,0 +1,112 @@
+package pb
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	parser "github.com/MemeLabs/chat-parser"
+	"github.com/davecgh/go-spew/spew"
+	"google.golang.org/protobuf/proto"
+)
+
+// fixtures holds the parse tests of the parser package, shared through its
+// testdata.
+type fixtures struct {
+	Context parser.ParserContextValues
+	Updates []parser.ContextUpdate
+	Tests   []struct {
+		Name  string `json:"name"`
+		Input string `json:"input"`
+	}
+}
+
+func readFixtures(t *testing.T) (*parser.ParserContext, fixtures) {
+	d, err := ioutil.ReadFile(filepath.Join("..", "testdata", "wasm", "fixtures.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f fixtures
+	if err := json.Unmarshal(d, &f); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := parser.NewParserContext(f.Context)
+	for _, u := range f.Updates {
+		if err := ctx.Update(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ctx, f
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctx, f := readFixtures(t)
+	if len(f.Tests) == 0 {
+		t.Fatal("no fixtures")
+	}
+
+	for _, test := range f.Tests {
+		ast := parser.NewParser(ctx, parser.NewLexer(test.Input)).ParseMessage()
+
+		p, err := SpanToProto(ast)
+		if err != nil {
+			t.Fatalf("%s: %s", test.Name, err)
+		}
+		b, err := proto.Marshal(p)
+		if err != nil {
+			t.Fatalf("%s: %s", test.Name, err)
+		}
+		var decoded Span
+		if err := proto.Unmarshal(b, &decoded); err != nil {
+			t.Fatalf("%s: %s", test.Name, err)
+		}
+
+		if rt := SpanFromProto(&decoded); !reflect.DeepEqual(ast, rt) {
+			t.Errorf("%s: got\n%s\nexpected\n%s", test.Name, spew.Sdump(rt), spew.Sdump(ast))
+		}
+	}
+}
+
+func TestRoundTripModifierArgs(t *testing.T) {
+	ast := &parser.Span{
+		Type: parser.SpanMessage,
+		Nodes: []parser.Node{
+			&parser.Emote{
+				Name:         "PEPE",
+				Modifiers:    []string{"wide", "hue", "rain"},
+				ModifierArgs: [][]string{nil, {"1", ""}, {}},
+				Rejected: []*parser.RejectedModifier{
+					{Name: "spin", Args: []string{}, Reason: parser.ModifierTooMany, TokPos: 20, TokEnd: 27},
+				},
+				TokEnd: 27,
+			},
+			&parser.Nick{Nick: "abeous", Meta: map[string]interface{}{"mod": true}, TokPos: 28, TokEnd: 34},
+		},
+		TokEnd:  34,
+		Limited: parser.LimitNodes,
+	}
+
+	p, err := SpanToProto(ast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := proto.Marshal(p)
+	var decoded Span
+	if err := proto.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if rt := SpanFromProto(&decoded); !reflect.DeepEqual(ast, rt) {
+		t.Errorf("got\n%s\nexpected\n%s", spew.Sdump(rt), spew.Sdump(ast))
+	}
+}
+
+func TestNickMetaError(t *testing.T) {
+	_, err := NodeToProto(&parser.Nick{Nick: "abeous", Meta: struct{}{}})
+	if err == nil {
+		t.Error("expected unsupported meta to fail")
+	}
+}
@@ -0,0 +1,6 @@
+// Package pb defines the protobuf form of the parser's AST and a gRPC service
+// that parses messages. It is a separate module so the parser itself does not
+// depend on protobuf or gRPC.
+package pb
+
+//go:generate protoc --go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. chat.proto
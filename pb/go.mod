@@ -0,0 +1,20 @@
+module github.com/MemeLabs/chat-parser/pb
+
+go 1.24.0
+
+require (
+	github.com/MemeLabs/chat-parser v0.0.0-00010101000000-000000000000
+	github.com/davecgh/go-spew v1.1.1
+	google.golang.org/grpc v1.80.0
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	github.com/petar/GoLLRB v0.0.0-20210522233825-ae3b015fd3e9 // indirect
+	golang.org/x/net v0.49.0 // indirect
+	golang.org/x/sys v0.40.0 // indirect
+	golang.org/x/text v0.33.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260120221211-b8f7ae30c516 // indirect
+)
+
+replace github.com/MemeLabs/chat-parser => ../
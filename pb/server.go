@@ -0,0 +1,49 @@
+package pb
+
+import (
+	"context"
+
+	parser "github.com/MemeLabs/chat-parser"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer returns a ChatParser service that parses messages with ctx.
+// Batches are parsed on up to workers goroutines, or GOMAXPROCS when workers
+// is zero.
+func NewServer(ctx *parser.ParserContext, workers int) *Server {
+	return &Server{
+		ctx:     ctx,
+		workers: workers,
+	}
+}
+
+type Server struct {
+	UnimplementedChatParserServer
+	ctx     *parser.ParserContext
+	workers int
+}
+
+func (s *Server) Parse(c context.Context, req *ParseRequest) (*ParseResponse, error) {
+	msg := parser.NewParser(s.ctx, parser.NewLexer(req.Input)).ParseMessage()
+	p, err := SpanToProto(msg)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &ParseResponse{Message: p}, nil
+}
+
+func (s *Server) ParseBatch(c context.Context, req *ParseBatchRequest) (*ParseBatchResponse, error) {
+	msgs, err := parser.ParseBatch(c, s.ctx, req.Inputs, s.workers)
+	if err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	res := &ParseBatchResponse{Messages: make([]*Span, len(msgs))}
+	for i, msg := range msgs {
+		if res.Messages[i], err = SpanToProto(msg); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	return res, nil
+}
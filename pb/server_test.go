@@ -0,0 +1,65 @@
+package pb
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	parser "github.com/MemeLabs/chat-parser"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestClient(t *testing.T, ctx *parser.ParserContext) ChatParserClient {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	RegisterChatParserServer(s, NewServer(ctx, 2))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewChatParserClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	ctx, f := readFixtures(t)
+	client := newTestClient(t, ctx)
+
+	var inputs []string
+	for _, test := range f.Tests {
+		inputs = append(inputs, test.Input)
+	}
+
+	batch, err := client.ParseBatch(context.Background(), &ParseBatchRequest{Inputs: inputs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.Messages) != len(inputs) {
+		t.Fatalf("got %d messages expected %d", len(batch.Messages), len(inputs))
+	}
+
+	for i, input := range inputs {
+		res, err := client.Parse(context.Background(), &ParseRequest{Input: input})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := parser.NewParser(ctx, parser.NewLexer(input)).ParseMessage()
+		if msg := SpanFromProto(res.Message); !reflect.DeepEqual(expected, msg) {
+			t.Errorf("%s: unexpected Parse result", f.Tests[i].Name)
+		}
+		if msg := SpanFromProto(batch.Messages[i]); !reflect.DeepEqual(expected, msg) {
+			t.Errorf("%s: unexpected ParseBatch result", f.Tests[i].Name)
+		}
+	}
+}
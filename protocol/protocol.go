@@ -0,0 +1,156 @@
+// Package protocol decodes and encodes the websocket frames of the
+// strims/destiny.gg chat protocol. A frame is a command followed by a space
+// and a JSON payload:
+//
+//	MSG {"nick":"abeous","features":[],"timestamp":1600000000000,"data":"PEPE"}
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	parser "github.com/MemeLabs/chat-parser"
+)
+
+const (
+	CommandMsg     = "MSG"
+	CommandPrivMsg = "PRIVMSG"
+	CommandJoin    = "JOIN"
+	CommandQuit    = "QUIT"
+	CommandNames   = "NAMES"
+)
+
+var ErrMalformedFrame = errors.New("malformed frame")
+
+type User struct {
+	Nick     string   `json:"nick"`
+	Features []string `json:"features"`
+}
+
+// Message is the payload of MSG and PRIVMSG frames. AST is set by Decoder.
+type Message struct {
+	Nick      string   `json:"nick"`
+	Features  []string `json:"features,omitempty"`
+	Timestamp int64    `json:"timestamp"`
+	Data      string   `json:"data"`
+	MessageID int64    `json:"messageid,omitempty"`
+
+	AST *parser.Span `json:"-"`
+}
+
+// Presence is the payload of JOIN and QUIT frames.
+type Presence struct {
+	Nick      string   `json:"nick"`
+	Features  []string `json:"features"`
+	Timestamp int64    `json:"timestamp"`
+}
+
+// Names is the payload of the NAMES frame listing the connected users.
+type Names struct {
+	ConnectionCount int    `json:"connectioncount"`
+	Users           []User `json:"users"`
+}
+
+// Frame is a decoded frame. The payload of known commands is decoded into
+// the matching field; Payload always holds the raw JSON.
+type Frame struct {
+	Command  string
+	Payload  json.RawMessage
+	Message  *Message
+	Presence *Presence
+	Names    *Names
+}
+
+// DecodeFrame decodes a frame without parsing message data.
+func DecodeFrame(b []byte) (*Frame, error) {
+	i := bytes.IndexByte(b, ' ')
+	if i <= 0 {
+		return nil, fmt.Errorf("%w: missing command", ErrMalformedFrame)
+	}
+	f := &Frame{
+		Command: string(b[:i]),
+		Payload: append(json.RawMessage(nil), b[i+1:]...),
+	}
+
+	var v interface{}
+	switch f.Command {
+	case CommandMsg, CommandPrivMsg:
+		f.Message = &Message{}
+		v = f.Message
+	case CommandJoin, CommandQuit:
+		f.Presence = &Presence{}
+		v = f.Presence
+	case CommandNames:
+		f.Names = &Names{}
+		v = f.Names
+	default:
+		return f, nil
+	}
+	if err := json.Unmarshal(f.Payload, v); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrMalformedFrame, f.Command, err)
+	}
+	return f, nil
+}
+
+// Encode encodes a frame. The decoded payload of known commands takes
+// precedence over Payload.
+func (f *Frame) Encode() ([]byte, error) {
+	var v interface{}
+	switch {
+	case f.Message != nil:
+		v = f.Message
+	case f.Presence != nil:
+		v = f.Presence
+	case f.Names != nil:
+		v = f.Names
+	default:
+		v = f.Payload
+	}
+
+	p, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 0, len(f.Command)+1+len(p))
+	b = append(b, f.Command...)
+	b = append(b, ' ')
+	return append(b, p...), nil
+}
+
+// NewDecoder returns a decoder that keeps the nicks of ctx in sync with the
+// users in the chat.
+func NewDecoder(ctx *parser.ParserContext) *Decoder {
+	return &Decoder{ctx: ctx}
+}
+
+// Decoder decodes frames, updates ctx.Nicks from NAMES, JOIN and QUIT frames
+// and parses the data of messages into their AST. NAMES and JOIN frames keep
+// the metadata of nicks that are already known.
+type Decoder struct {
+	ctx *parser.ParserContext
+}
+
+func (d *Decoder) Decode(b []byte) (*Frame, error) {
+	f, err := DecodeFrame(b)
+	if err != nil {
+		return nil, err
+	}
+
+	switch f.Command {
+	case CommandMsg, CommandPrivMsg:
+		f.Message.AST = parser.NewParser(d.ctx, parser.NewLexer(f.Message.Data)).ParseMessage()
+	case CommandJoin:
+		d.ctx.Nicks.InsertNoReplace([]rune(f.Presence.Nick))
+	case CommandQuit:
+		d.ctx.Nicks.Remove([]rune(f.Presence.Nick))
+	case CommandNames:
+		nicks := make([][]rune, len(f.Names.Users))
+		for i, u := range f.Names.Users {
+			nicks[i] = []rune(u.Nick)
+		}
+		d.ctx.Nicks.Replace(nicks)
+	}
+	return f, nil
+}
@@ -0,0 +1,126 @@
+package protocol
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	parser "github.com/MemeLabs/chat-parser"
+)
+
+func TestDecodeFrame(t *testing.T) {
+	cases := []struct {
+		name     string
+		frame    string
+		expected *Frame
+	}{
+		{"msg", `MSG {"nick":"abeous","features":["moderator"],"timestamp":1600000000000,"data":"PEPE"}`, &Frame{
+			Command: CommandMsg,
+			Message: &Message{Nick: "abeous", Features: []string{"moderator"}, Timestamp: 1600000000000, Data: "PEPE"},
+		}},
+		{"privmsg", `PRIVMSG {"nick":"wrxst","timestamp":1,"data":"hi","messageid":7}`, &Frame{
+			Command: CommandPrivMsg,
+			Message: &Message{Nick: "wrxst", Timestamp: 1, Data: "hi", MessageID: 7},
+		}},
+		{"join", `JOIN {"nick":"abeous","features":[],"timestamp":2}`, &Frame{
+			Command:  CommandJoin,
+			Presence: &Presence{Nick: "abeous", Features: []string{}, Timestamp: 2},
+		}},
+		{"names", `NAMES {"connectioncount":3,"users":[{"nick":"a","features":[]},{"nick":"b","features":["bot"]}]}`, &Frame{
+			Command: CommandNames,
+			Names: &Names{ConnectionCount: 3, Users: []User{
+				{"a", []string{}},
+				{"b", []string{"bot"}},
+			}},
+		}},
+		{"unknown", `PING {"data":1}`, &Frame{Command: "PING"}},
+	}
+
+	for _, c := range cases {
+		f, err := DecodeFrame([]byte(c.frame))
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", c.name, err)
+			continue
+		}
+		if string(f.Payload) != c.frame[len(c.expected.Command)+1:] {
+			t.Errorf("%s: unexpected payload %s", c.name, f.Payload)
+		}
+		f.Payload = nil
+		if !reflect.DeepEqual(c.expected, f) {
+			t.Errorf("%s: got %+v expected %+v", c.name, f, c.expected)
+		}
+	}
+}
+
+func TestDecodeFrameErrors(t *testing.T) {
+	for _, frame := range []string{"", "MSG", " {}", `MSG {"nick":`, `NAMES []`} {
+		if _, err := DecodeFrame([]byte(frame)); !errors.Is(err, ErrMalformedFrame) {
+			t.Errorf("%q: expected malformed frame error, got %v", frame, err)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	frames := []string{
+		`MSG {"nick":"abeous","features":["moderator"],"timestamp":1600000000000,"data":"PEPE"}`,
+		`JOIN {"nick":"abeous","features":[],"timestamp":2}`,
+		`NAMES {"connectioncount":1,"users":[{"nick":"a","features":[]}]}`,
+		`PING {"data":1}`,
+	}
+	for _, frame := range frames {
+		f, err := DecodeFrame([]byte(frame))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := f.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != frame {
+			t.Errorf("got %s expected %s", b, frame)
+		}
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	ctx := parser.NewParserContext(parser.ParserContextValues{
+		Emotes: []string{"PEPE"},
+		Nicks:  []string{"stale"},
+	})
+	ctx.Nicks.InsertWithMeta([]rune("abeous"), "mod")
+	d := NewDecoder(ctx)
+
+	frames := []string{
+		`NAMES {"connectioncount":2,"users":[{"nick":"abeous","features":[]},{"nick":"wrxst","features":[]}]}`,
+		`JOIN {"nick":"Bob","features":[],"timestamp":1}`,
+		`JOIN {"nick":"abeous","features":[],"timestamp":1}`,
+		`QUIT {"nick":"wrxst","features":[],"timestamp":2}`,
+	}
+	for _, frame := range frames {
+		if _, err := d.Decode([]byte(frame)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for nick, expected := range map[string]bool{"stale": false, "abeous": true, "wrxst": false, "bob": true} {
+		if ctx.Nicks.Contains([]rune(nick)) != expected {
+			t.Errorf("%s: expected present %t", nick, expected)
+		}
+	}
+
+	f, err := d.Decode([]byte(`MSG {"nick":"abeous","timestamp":3,"data":"PEPE bob abeous"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &parser.Span{
+		Type: parser.SpanMessage,
+		Nodes: []parser.Node{
+			&parser.Emote{Name: "PEPE", TokPos: 0, TokEnd: 4},
+			&parser.Nick{Nick: "Bob", TokPos: 5, TokEnd: 8},
+			&parser.Nick{Nick: "abeous", TokPos: 9, TokEnd: 15, Meta: "mod"},
+		},
+		TokEnd: 15,
+	}
+	if !reflect.DeepEqual(expected, f.Message.AST) {
+		t.Errorf("unexpected AST %+v", f.Message.AST)
+	}
+}
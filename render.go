@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"html"
+	"strings"
+)
+
+// RenderHTML renders a parsed message as HTML. Span delimiters and escape
+// characters are dropped, text is escaped and nodes become elements with
+// chat-* classes for styling. Blocked links are rendered as plain text.
+func RenderHTML(input string, msg *Span) string {
+	r := &htmlRenderer{input: []rune(input)}
+	r.node(msg)
+	return r.b.String()
+}
+
+type htmlRenderer struct {
+	input []rune
+	b     strings.Builder
+}
+
+var spanElements = map[SpanType][2]string{
+	SpanGreentext: {`<span class="chat-greentext">`, `</span>`},
+	SpanSpoiler:   {`<span class="chat-spoiler">`, `</span>`},
+	SpanMe:        {`<span class="chat-me">`, `</span>`},
+	SpanCode:      {`<code>`, `</code>`},
+}
+
+func (r *htmlRenderer) node(n Node) {
+	switch n := n.(type) {
+	case *Span:
+		r.span(n)
+	case *Emote:
+		r.b.WriteString(`<span class="chat-emote chat-emote-`)
+		r.b.WriteString(html.EscapeString(n.Name))
+		for _, m := range n.Modifiers {
+			r.b.WriteString(` chat-emote-mod-`)
+			r.b.WriteString(html.EscapeString(m))
+		}
+		r.b.WriteString(`" title="`)
+		r.b.WriteString(html.EscapeString(n.Name))
+		r.b.WriteString(`">`)
+		r.b.WriteString(html.EscapeString(n.Name))
+		r.b.WriteString(`</span>`)
+	case *Emoji:
+		r.b.WriteString(`<span class="chat-emoji">`)
+		r.b.WriteString(html.EscapeString(string(n.Codepoints)))
+		r.b.WriteString(`</span>`)
+	case *Nick:
+		r.b.WriteString(`<span class="chat-nick" data-nick="`)
+		r.b.WriteString(html.EscapeString(n.Nick))
+		r.b.WriteString(`">`)
+		r.text(n.TokPos, n.TokEnd)
+		r.b.WriteString(`</span>`)
+	case *Tag:
+		r.b.WriteString(`<span class="chat-tag chat-tag-`)
+		r.b.WriteString(html.EscapeString(n.Name))
+		r.b.WriteString(`">`)
+		r.text(n.TokPos, n.TokEnd)
+		r.b.WriteString(`</span>`)
+	case *ChannelRef:
+		r.b.WriteString(`<span class="chat-channel" data-channel="`)
+		r.b.WriteString(html.EscapeString(n.Channel))
+		r.b.WriteString(`">#`)
+		r.b.WriteString(html.EscapeString(n.DisplayName))
+		r.b.WriteString(`</span>`)
+	case *Link:
+		r.link(n)
+	}
+}
+
+func (r *htmlRenderer) span(s *Span) {
+	e, ok := spanElements[s.Type]
+	r.b.WriteString(e[0])
+
+	open, close := spanMarkers(r.input, s)
+	if s.Type == SpanCode {
+		r.b.WriteString(html.EscapeString(string(r.input[open:close])))
+	} else {
+		pos := open
+		for _, n := range s.Nodes {
+			r.text(pos, n.Pos())
+			r.node(n)
+			pos = n.End()
+		}
+		r.text(pos, close)
+	}
+
+	if ok {
+		r.b.WriteString(e[1])
+	}
+}
+
+func (r *htmlRenderer) link(l *Link) {
+	class := strings.ToLower(l.Class.String())
+	if l.Class == LinkBlocked {
+		r.b.WriteString(`<span class="chat-link chat-link-blocked">`)
+		r.text(l.TokPos, l.TokEnd)
+		r.b.WriteString(`</span>`)
+		return
+	}
+
+	href := l.URL
+	if !strings.Contains(href, "://") {
+		href = "http://" + href
+	}
+	r.b.WriteString(`<a class="chat-link chat-link-`)
+	r.b.WriteString(class)
+	r.b.WriteString(`" href="`)
+	r.b.WriteString(html.EscapeString(href))
+	r.b.WriteString(`" rel="nofollow noopener noreferrer" target="_blank">`)
+	r.b.WriteString(html.EscapeString(l.URL))
+	r.b.WriteString(`</a>`)
+}
+
+// text writes input[pos:end] without escape characters.
+func (r *htmlRenderer) text(pos, end int) {
+	if end > len(r.input) {
+		end = len(r.input)
+	}
+	start := pos
+	for i := pos; i < end; i++ {
+		if r.input[i] == '\\' && i+1 < end {
+			r.b.WriteString(html.EscapeString(string(r.input[start:i])))
+			start = i + 1
+			i++
+		}
+	}
+	if start < end {
+		r.b.WriteString(html.EscapeString(string(r.input[start:end])))
+	}
+}
@@ -0,0 +1,32 @@
+package parser
+
+import "testing"
+
+func TestRenderHTML(t *testing.T) {
+	ctx := newTestParserContext()
+	ctx.LinkPolicy = NewLinkPolicy(LinkPolicyValues{Blocked: []string{"bad.com"}})
+
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"text", "a <b> & \\|| c", "a &lt;b&gt; &amp; || c"},
+		{"emote", "PEPE:wide hi", `<span class="chat-emote chat-emote-PEPE chat-emote-mod-wide" title="PEPE">PEPE</span> hi`},
+		{"nick", "hi @abeous", `hi <span class="chat-nick" data-nick="abeous">@abeous</span>`},
+		{"greentext", "> nsfw", `<span class="chat-greentext"> <span class="chat-tag chat-tag-nsfw">nsfw</span></span>`},
+		{"me", "/me waves", `<span class="chat-me">waves</span>`},
+		{"spoiler", "||a `<x>` b||", `<span class="chat-spoiler">a <code>&lt;x&gt;</code> b</span>`},
+		{"unclosed spoiler", "||a", `<span class="chat-spoiler">a</span>`},
+		{"link", "www.a.com/?x=1&y=2", `<a class="chat-link chat-link-allowed" href="http://www.a.com/?x=1&amp;y=2" rel="nofollow noopener noreferrer" target="_blank">www.a.com/?x=1&amp;y=2</a>`},
+		{"blocked link", "https://bad.com/x", `<span class="chat-link chat-link-blocked">https://bad.com/x</span>`},
+		{"channel", "#destiny 👍", `<span class="chat-channel" data-channel="destiny">#Destiny</span> <span class="chat-emoji">👍</span>`},
+	}
+
+	for _, c := range cases {
+		p := NewParser(ctx, NewLexer(c.input))
+		if html := RenderHTML(c.input, p.ParseMessage()); html != c.expected {
+			t.Errorf("%s: got\n%s\nexpected\n%s", c.name, html, c.expected)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"unicode"
+
+	"golang.org/x/text/unicode/rangetable"
+)
+
+type SanitizeKind int
+
+const (
+	SanitizeZalgo SanitizeKind = iota
+	SanitizeInvisible
+	SanitizeBidi
+)
+
+var sanitizeKindNames = map[SanitizeKind]string{
+	SanitizeZalgo:     "Zalgo",
+	SanitizeInvisible: "Invisible",
+	SanitizeBidi:      "Bidi",
+}
+
+func (k SanitizeKind) String() string {
+	return sanitizeKindNames[k]
+}
+
+// bidiControl contains the explicit directional formatting characters.
+var bidiControl = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x061c, 0x061c, 1},
+		{0x200e, 0x200f, 1},
+		{0x202a, 0x202e, 1},
+		{0x2066, 0x2069, 1},
+	},
+}
+
+// invisibleFiller contains characters outside of Cf that render as blank
+// space and are commonly used to forge empty or look-alike names.
+var invisibleFiller = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x115f, 0x1160, 1},
+		{0x2800, 0x3164, 2404},
+		{0xffa0, 0xffa0, 1},
+	},
+}
+
+var combiningMark = rangetable.Merge(unicode.Mn, unicode.Me)
+
+type SanitizeOptions struct {
+	// MaxCombiningMarks is the number of combining marks allowed on a single
+	// base character. Marks beyond it are reported as zalgo. Zero disables
+	// zalgo detection.
+	MaxCombiningMarks int
+	StripZalgo        bool
+	StripInvisible    bool
+	StripBidi         bool
+}
+
+// SanitizeFinding is a run of suspicious characters at input[TokPos:TokEnd].
+type SanitizeFinding struct {
+	Kind     SanitizeKind
+	TokPos   int
+	TokEnd   int
+	Stripped bool
+}
+
+func (o SanitizeOptions) strip(k SanitizeKind) bool {
+	switch k {
+	case SanitizeZalgo:
+		return o.StripZalgo
+	case SanitizeInvisible:
+		return o.StripInvisible
+	case SanitizeBidi:
+		return o.StripBidi
+	}
+	return false
+}
+
+func sanitizeKind(r rune) (SanitizeKind, bool) {
+	switch {
+	case unicode.Is(bidiControl, r):
+		return SanitizeBidi, true
+	case unicode.Is(unicode.Cf, r), unicode.Is(invisibleFiller, r):
+		return SanitizeInvisible, true
+	}
+	return 0, false
+}
+
+// Sanitize detects stacked combining marks, invisible format characters and
+// bidi overrides in input and removes the kinds selected in opt. Emoji
+// sequences are left intact. Findings are reported with rune offsets into
+// input.
+func Sanitize(input string, opt SanitizeOptions) (string, []SanitizeFinding) {
+	runes := []rune(input)
+	out := make([]rune, 0, len(runes))
+	var findings []SanitizeFinding
+
+	report := func(k SanitizeKind, pos int) {
+		stripped := opt.strip(k)
+		if n := len(findings); n != 0 && findings[n-1].Kind == k && findings[n-1].TokEnd == pos {
+			findings[n-1].TokEnd++
+		} else {
+			findings = append(findings, SanitizeFinding{
+				Kind:     k,
+				TokPos:   pos,
+				TokEnd:   pos + 1,
+				Stripped: stripped,
+			})
+		}
+		if !stripped {
+			out = append(out, runes[pos])
+		}
+	}
+
+	var marks int
+	for i := 0; i < len(runes); i++ {
+		if n := emojiLen(runes, i); n != 0 {
+			out = append(out, runes[i:i+n]...)
+			i += n - 1
+			marks = 0
+			continue
+		}
+
+		r := runes[i]
+		if k, ok := sanitizeKind(r); ok {
+			report(k, i)
+			continue
+		}
+
+		if unicode.Is(combiningMark, r) {
+			marks++
+			if opt.MaxCombiningMarks != 0 && marks > opt.MaxCombiningMarks {
+				report(SanitizeZalgo, i)
+				continue
+			}
+		} else {
+			marks = 0
+		}
+		out = append(out, r)
+	}
+
+	return string(out), findings
+}
@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	strip := SanitizeOptions{
+		MaxCombiningMarks: 2,
+		StripZalgo:        true,
+		StripInvisible:    true,
+		StripBidi:         true,
+	}
+
+	cases := []struct {
+		name     string
+		input    string
+		opt      SanitizeOptions
+		output   string
+		findings []SanitizeFinding
+	}{
+		{"clean", "héllo 👩\u200d👩\u200d👧 ❤\ufe0f", strip, "héllo 👩\u200d👩\u200d👧 ❤\ufe0f", nil},
+		{"zalgo", "z\u0301\u0302\u0303\u0304a\u0301", strip, "z\u0301\u0302a\u0301", []SanitizeFinding{
+			{SanitizeZalgo, 3, 5, true},
+		}},
+		{"zero width in nick", "abe\u200b\u200bous", strip, "abeous", []SanitizeFinding{
+			{SanitizeInvisible, 3, 5, true},
+		}},
+		{"bidi override", "\u202eabeous\u202c", strip, "abeous", []SanitizeFinding{
+			{SanitizeBidi, 0, 1, true},
+			{SanitizeBidi, 7, 8, true},
+		}},
+		{"detect only", "a\u200db\u202e", SanitizeOptions{StripBidi: true}, "a\u200db", []SanitizeFinding{
+			{SanitizeInvisible, 1, 2, false},
+			{SanitizeBidi, 3, 4, true},
+		}},
+	}
+
+	for _, c := range cases {
+		output, findings := Sanitize(c.input, c.opt)
+		if output != c.output {
+			t.Errorf("%s: got %q expected %q", c.name, output, c.output)
+		}
+		if !reflect.DeepEqual(findings, c.findings) {
+			t.Errorf("%s: got %v expected %v", c.name, findings, c.findings)
+		}
+	}
+}
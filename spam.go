@@ -0,0 +1,157 @@
+package parser
+
+import "unicode"
+
+// SpamMetrics are the per heuristic measurements of a message.
+type SpamMetrics struct {
+	Runes    int
+	Emotes   int
+	Emoji    int
+	Mentions int
+	Spoilers int
+	Code     int
+	// Depth is the deepest span nesting below the message.
+	Depth int
+	// UppercaseRatio is the share of letters that are upper case.
+	UppercaseRatio float64
+	// DistinctRatio is the share of distinct runes among non space runes.
+	DistinctRatio float64
+	// LongestRun is the length of the longest run of one repeated rune.
+	LongestRun int
+}
+
+// SpamRule scores one metric. A message is penalized Weight for every unit
+// its metric exceeds Threshold.
+type SpamRule struct {
+	Threshold float64
+	Weight    float64
+}
+
+func (r SpamRule) score(v float64) float64 {
+	if v <= r.Threshold {
+		return 0
+	}
+	return (v - r.Threshold) * r.Weight
+}
+
+// SpamConfig weighs the heuristics combined into a message's spam score.
+type SpamConfig struct {
+	Emotes   SpamRule
+	Emoji    SpamRule
+	Mentions SpamRule
+	Spans    SpamRule
+	Depth    SpamRule
+	// Uppercase and Repetition scores are only applied to messages with at
+	// least MinRunes runes. Repetition is scored as 1 - DistinctRatio.
+	Uppercase  SpamRule
+	Repetition SpamRule
+	LongestRun SpamRule
+	MinRunes   int
+}
+
+// DefaultSpamConfig is a starting point for tuning SpamConfig.
+var DefaultSpamConfig = SpamConfig{
+	Emotes:     SpamRule{Threshold: 6, Weight: 1},
+	Emoji:      SpamRule{Threshold: 8, Weight: 0.5},
+	Mentions:   SpamRule{Threshold: 3, Weight: 2},
+	Spans:      SpamRule{Threshold: 4, Weight: 1},
+	Depth:      SpamRule{Threshold: 2, Weight: 2},
+	Uppercase:  SpamRule{Threshold: 0.7, Weight: 20},
+	Repetition: SpamRule{Threshold: 0.8, Weight: 20},
+	LongestRun: SpamRule{Threshold: 10, Weight: 0.2},
+	MinRunes:   12,
+}
+
+// SpamReport is the result of analyzing a message.
+type SpamReport struct {
+	SpamMetrics
+	Score float64
+}
+
+// AnalyzeSpam measures msg, the parsed form of input, and scores it with c.
+func AnalyzeSpam(c SpamConfig, input string, msg *Span) SpamReport {
+	var m SpamMetrics
+	measureNodes(&m, msg)
+	measureText(&m, []rune(input))
+
+	score := c.Emotes.score(float64(m.Emotes)) +
+		c.Emoji.score(float64(m.Emoji)) +
+		c.Mentions.score(float64(m.Mentions)) +
+		c.Spans.score(float64(m.Spoilers+m.Code)) +
+		c.Depth.score(float64(m.Depth)) +
+		c.LongestRun.score(float64(m.LongestRun))
+	if m.Runes >= c.MinRunes {
+		score += c.Uppercase.score(m.UppercaseRatio) +
+			c.Repetition.score(1-m.DistinctRatio)
+	}
+
+	return SpamReport{
+		SpamMetrics: m,
+		Score:       score,
+	}
+}
+
+func measureNodes(m *SpamMetrics, msg *Span) {
+	var depth int
+	Inspect(msg, func(n Node) bool {
+		switch n := n.(type) {
+		case nil:
+			depth--
+		case *Span:
+			if depth > m.Depth {
+				m.Depth = depth
+			}
+			depth++
+			switch n.Type {
+			case SpanSpoiler:
+				m.Spoilers++
+			case SpanCode:
+				m.Code++
+			}
+			return true
+		case *Emote:
+			m.Emotes++
+		case *Emoji:
+			m.Emoji++
+		case *Nick:
+			m.Mentions++
+		}
+		return false
+	})
+}
+
+func measureText(m *SpamMetrics, input []rune) {
+	var letters, upper, nonSpace, run int
+	distinct := make(map[rune]struct{})
+	for i, r := range input {
+		if i > 0 && input[i-1] == r {
+			run++
+		} else {
+			run = 1
+		}
+		if run > m.LongestRun {
+			m.LongestRun = run
+		}
+
+		if unicode.IsSpace(r) {
+			continue
+		}
+		nonSpace++
+		distinct[r] = struct{}{}
+
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+
+	m.Runes = len(input)
+	if letters != 0 {
+		m.UppercaseRatio = float64(upper) / float64(letters)
+	}
+	if nonSpace != 0 {
+		m.DistinctRatio = float64(len(distinct)) / float64(nonSpace)
+	}
+}
@@ -0,0 +1,48 @@
+package parser
+
+import "testing"
+
+func TestAnalyzeSpam(t *testing.T) {
+	ctx := NewParserContext(ParserContextValues{
+		Emotes: []string{"PEPE", "CuckCrab"},
+		Nicks:  []string{"abeous", "wrxst"},
+	})
+
+	cases := []struct {
+		name     string
+		input    string
+		expected SpamMetrics
+		spam     bool
+	}{
+		{
+			"normal",
+			"hey @abeous how is it going PEPE",
+			SpamMetrics{Runes: 32, Emotes: 1, Mentions: 1, UppercaseRatio: 4.0 / 25, DistinctRatio: 16.0 / 26, LongestRun: 1},
+			false,
+		},
+		{
+			"emote wall",
+			"PEPE PEPE PEPE PEPE PEPE PEPE PEPE PEPE PEPE PEPE",
+			SpamMetrics{Runes: 49, Emotes: 10, UppercaseRatio: 1, DistinctRatio: 2.0 / 40, LongestRun: 1},
+			true,
+		},
+		{
+			"caps and spans",
+			"WHY ||IS|| `THIS` ||ALL|| ||CAPS||",
+			SpamMetrics{Runes: 34, Spoilers: 3, Code: 1, Depth: 1, UppercaseRatio: 1, DistinctRatio: 12.0 / 30, LongestRun: 2},
+			true,
+		},
+	}
+
+	for _, c := range cases {
+		p := NewParser(ctx, NewLexer(c.input))
+		r := AnalyzeSpam(DefaultSpamConfig, c.input, p.ParseMessage())
+
+		if r.SpamMetrics != c.expected {
+			t.Errorf("%s: got %+v expected %+v", c.name, r.SpamMetrics, c.expected)
+		}
+		if spam := r.Score > 5; spam != c.spam {
+			t.Errorf("%s: unexpected score %f", c.name, r.Score)
+		}
+	}
+}
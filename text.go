@@ -0,0 +1,166 @@
+package parser
+
+import "unicode"
+
+var spoilerMarker = []rune("||")
+
+// isEscaped reports whether input[i] is preceded by an odd number of
+// backslashes.
+func isEscaped(input []rune, i int) bool {
+	var n int
+	for j := i - 1; j >= 0 && input[j] == '\\'; j-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+func hasMarkerAt(input []rune, i int, m []rune) bool {
+	if i < 0 || i+len(m) > len(input) || isEscaped(input, i) {
+		return false
+	}
+	return compareRuneSlices(input[i:i+len(m)], m) == 0
+}
+
+// spanMarkers returns the bounds of the markup delimiting s. The opening
+// marker is input[s.TokPos:open] and the closing marker is
+// input[close:s.TokEnd]; either may be empty when the span has no marker or
+// was left unclosed. The prefix of SpanMe messages lies before s.TokPos and is
+// not included.
+func spanMarkers(input []rune, s *Span) (open, close int) {
+	open, close = s.TokPos, s.TokEnd
+	switch s.Type {
+	case SpanGreentext:
+		open++
+	case SpanSpoiler:
+		open += len(spoilerMarker)
+		if close-open >= len(spoilerMarker) && hasMarkerAt(input, close-len(spoilerMarker), spoilerMarker) {
+			close -= len(spoilerMarker)
+		}
+	case SpanCode:
+		open++
+		if close > open && close <= len(input) && input[close-1] == '`' && !isEscaped(input, close-1) {
+			close--
+		}
+	}
+	return
+}
+
+type textMode int
+
+const (
+	textInclude textMode = iota
+	textSkip
+	textSeparate
+	textBreak
+)
+
+// textBreakRune marks a position in a text view that words and phrases must
+// not span.
+const textBreakRune rune = eof
+
+// textView is the visible text of a message with span markup and escape
+// characters removed. Nodes can be replaced with a separator or a break.
+type textView struct {
+	text []rune
+	pos  []int
+}
+
+func newTextView(input []rune, msg *Span, mode func(Node) textMode) *textView {
+	modes := make([]textMode, len(input))
+	for i := 0; i < msg.TokPos && i < len(modes); i++ {
+		modes[i] = textSkip
+	}
+	for i := msg.TokEnd; i < len(modes); i++ {
+		modes[i] = textSkip
+	}
+
+	fill := func(pos, end int, m textMode) {
+		for i := pos; i < end && i < len(modes); i++ {
+			modes[i] = m
+		}
+	}
+	Inspect(msg, func(n Node) bool {
+		if n == nil {
+			return false
+		}
+		if m := mode(n); m != textInclude {
+			fill(n.Pos(), n.End(), m)
+			return false
+		}
+		if s, ok := n.(*Span); ok {
+			open, close := spanMarkers(input, s)
+			fill(s.TokPos, open, textSkip)
+			fill(close, s.TokEnd, textSkip)
+			return true
+		}
+		return false
+	})
+
+	v := &textView{
+		text: make([]rune, 0, len(input)),
+		pos:  make([]int, 0, len(input)),
+	}
+	for i := 0; i < len(input); i++ {
+		switch modes[i] {
+		case textInclude:
+			if input[i] == '\\' && i+1 < len(input) && modes[i+1] == textInclude {
+				i++
+			}
+			v.append(input[i], i)
+		case textSeparate, textBreak:
+			r := ' '
+			if modes[i] == textBreak {
+				r = textBreakRune
+			}
+			v.append(r, i)
+			for i+1 < len(input) && modes[i+1] == modes[i] {
+				i++
+			}
+		}
+	}
+	return v
+}
+
+func (v *textView) append(r rune, pos int) {
+	v.text = append(v.text, r)
+	v.pos = append(v.pos, pos)
+}
+
+func isWordRune(r rune) bool {
+	return r != textBreakRune && !unicode.Is(nonWord, r)
+}
+
+type textWord struct {
+	text     []rune
+	pos, end int
+	// joined is set when nothing but separators lie between this word and
+	// the previous one.
+	joined bool
+}
+
+// words splits the view into lower cased words.
+func (v *textView) words() (words []textWord) {
+	joined := false
+	for i := 0; i < len(v.text); i++ {
+		if !isWordRune(v.text[i]) {
+			if v.text[i] == textBreakRune {
+				joined = false
+			}
+			continue
+		}
+
+		j := i
+		for j+1 < len(v.text) && isWordRune(v.text[j+1]) {
+			j++
+		}
+		words = append(words, textWord{
+			text:   runeSliceToLower(v.text[i:j+1], nil),
+			pos:    v.pos[i],
+			end:    v.pos[j] + 1,
+			joined: joined,
+		})
+		joined = true
+		i = j
+	}
+	return
+}
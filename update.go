@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnknownUpdateOp = errors.New("unknown context update op")
+	ErrUnknownIndex    = errors.New("unknown context index")
+	ErrMissingIndex    = errors.New("context index not set")
+)
+
+// ContextUpdate inserts a value into or removes it from one of the indexes of
+// a ParserContext, or replaces the values of an index. It lets clients that
+// only exchange JSON, like the wasm build and the HTTP service, keep a
+// context in sync.
+type ContextUpdate struct {
+	// Op is "insert", "remove" or "replace". Replace sets the values of the
+	// emotes, emoteModifiers, nicks, tags or commands index to Values.
+	Op string `json:"op"`
+	// Index is one of "emotes", "emoteModifiers", "nicks", "tags",
+	// "shortcodes", "channels" or "commands".
+	Index  string   `json:"index"`
+	Value  string   `json:"value"`
+	Values []string `json:"values,omitempty"`
+	// Replacement is the emoji or emote an inserted shortcode stands for.
+	Replacement string `json:"replacement,omitempty"`
+	// DisplayName and Live describe an inserted channel.
+	DisplayName string `json:"displayName,omitempty"`
+	Live        bool   `json:"live,omitempty"`
+}
+
+// Update applies u to the context. Updates to an index the context was
+// created without fail with ErrMissingIndex; contexts from NewParserContext
+// have every index.
+func (c *ParserContext) Update(u ContextUpdate) error {
+	var insert, replace bool
+	switch u.Op {
+	case "insert":
+		insert = true
+	case "remove":
+	case "replace":
+		replace = true
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownUpdateOp, u.Op)
+	}
+
+	if c.missingIndex(u.Index) {
+		return fmt.Errorf("%w: %q", ErrMissingIndex, u.Index)
+	}
+	if replace {
+		return c.replace(u.Index, RunesFromStrings(u.Values))
+	}
+
+	v := []rune(u.Value)
+	switch u.Index {
+	case "emotes":
+		updateRuneIndex(c.Emotes, v, insert)
+	case "emoteModifiers":
+		updateRuneIndex(c.EmoteModifiers, v, insert)
+	case "tags":
+		updateRuneIndex(c.Tags, v, insert)
+	case "commands":
+		updateRuneIndex(c.Commands, v, insert)
+	case "nicks":
+		if insert {
+			c.Nicks.Insert(v)
+		} else {
+			c.Nicks.Remove(v)
+		}
+	case "shortcodes":
+		if insert {
+			c.Shortcodes.Insert(v, u.Replacement)
+		} else {
+			c.Shortcodes.Remove(v)
+		}
+	case "channels":
+		if insert {
+			c.Channels.InsertWithInfo(v, ChannelInfo{DisplayName: u.DisplayName, Live: u.Live})
+		} else {
+			c.Channels.Remove(v)
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownIndex, u.Index)
+	}
+	return nil
+}
+
+func (c *ParserContext) replace(index string, values [][]rune) error {
+	switch index {
+	case "emotes":
+		c.Emotes.Replace(values)
+	case "emoteModifiers":
+		c.EmoteModifiers.Replace(values)
+	case "tags":
+		c.Tags.Replace(values)
+	case "commands":
+		c.Commands.Replace(values)
+	case "nicks":
+		c.Nicks.Replace(values)
+	case "shortcodes", "channels":
+		return fmt.Errorf("%w: replace on %q", ErrUnknownUpdateOp, index)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownIndex, index)
+	}
+	return nil
+}
+
+// missingIndex reports whether the named index is nil.
+func (c *ParserContext) missingIndex(index string) bool {
+	switch index {
+	case "emotes":
+		return c.Emotes == nil
+	case "emoteModifiers":
+		return c.EmoteModifiers == nil
+	case "tags":
+		return c.Tags == nil
+	case "commands":
+		return c.Commands == nil
+	case "nicks":
+		return c.Nicks == nil
+	case "shortcodes":
+		return c.Shortcodes == nil
+	case "channels":
+		return c.Channels == nil
+	}
+	return false
+}
+
+// updateRuneIndex inserts or removes v, keeping the index free of duplicates.
+func updateRuneIndex(r *RuneIndex, v []rune, insert bool) {
+	if insert && !r.Contains(v) {
+		r.Insert(v)
+	} else if !insert && r.Contains(v) {
+		r.Remove(v)
+	}
+}
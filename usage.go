@@ -0,0 +1,234 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+func NewUsageStats() *UsageStats {
+	s := &UsageStats{}
+	s.init()
+	return s
+}
+
+// UsageStats counts the uses of emotes, modifiers, nicks, tags and links in a
+// set of messages. Every occurrence is counted, including repeats within a
+// message. The zero value is ready to use.
+type UsageStats struct {
+	Messages int
+	// Emotes counts all uses of each emote. PlainEmotes and ModifiedEmotes
+	// split them into uses without and with modifiers.
+	Emotes         map[string]int
+	PlainEmotes    map[string]int
+	ModifiedEmotes map[string]int
+	Modifiers      map[string]int
+	// Mentions counts @nick mentions and BareMentions nicks used without @,
+	// both by canonical nick.
+	Mentions     map[string]int
+	BareMentions map[string]int
+	Tags         map[string]int
+	// LinkDomains counts links by lower cased host name without a leading
+	// www.
+	LinkDomains map[string]int
+}
+
+// init allocates the count maps that are still nil.
+func (s *UsageStats) init() {
+	for _, m := range []*map[string]int{
+		&s.Emotes,
+		&s.PlainEmotes,
+		&s.ModifiedEmotes,
+		&s.Modifiers,
+		&s.Mentions,
+		&s.BareMentions,
+		&s.Tags,
+		&s.LinkDomains,
+	} {
+		if *m == nil {
+			*m = map[string]int{}
+		}
+	}
+}
+
+// Add counts the nodes of msg, which was parsed from input.
+func (s *UsageStats) Add(input string, msg *Span) {
+	s.init()
+	runes := []rune(input)
+	s.Messages++
+
+	Inspect(msg, func(n Node) bool {
+		switch n := n.(type) {
+		case *Emote:
+			s.Emotes[n.Name]++
+			if len(n.Modifiers) == 0 {
+				s.PlainEmotes[n.Name]++
+			} else {
+				s.ModifiedEmotes[n.Name]++
+			}
+			for _, m := range n.Modifiers {
+				s.Modifiers[m]++
+			}
+		case *Nick:
+			if n.TokPos < len(runes) && runes[n.TokPos] == '@' {
+				s.Mentions[n.Nick]++
+			} else {
+				s.BareMentions[n.Nick]++
+			}
+		case *Tag:
+			s.Tags[n.Name]++
+		case *Link:
+			if _, host, ok := parseLinkURL(n.URL); ok {
+				s.LinkDomains[strings.TrimPrefix(host, "www.")]++
+			}
+		}
+		return true
+	})
+}
+
+// Merge adds the counts of o to s.
+func (s *UsageStats) Merge(o *UsageStats) {
+	s.init()
+	s.Messages += o.Messages
+	mergeCounts(s.Emotes, o.Emotes)
+	mergeCounts(s.PlainEmotes, o.PlainEmotes)
+	mergeCounts(s.ModifiedEmotes, o.ModifiedEmotes)
+	mergeCounts(s.Modifiers, o.Modifiers)
+	mergeCounts(s.Mentions, o.Mentions)
+	mergeCounts(s.BareMentions, o.BareMentions)
+	mergeCounts(s.Tags, o.Tags)
+	mergeCounts(s.LinkDomains, o.LinkDomains)
+}
+
+func mergeCounts(dst, src map[string]int) {
+	for k, v := range src {
+		dst[k] += v
+	}
+}
+
+// UsageCount is a key of a usage count map with its count.
+type UsageCount struct {
+	Key   string
+	Count int
+}
+
+// TopUsage returns the n largest counts in descending order. Ties are ordered
+// by key. A negative n returns all counts.
+func TopUsage(counts map[string]int, n int) []UsageCount {
+	top := make([]UsageCount, 0, len(counts))
+	for k, v := range counts {
+		top = append(top, UsageCount{k, v})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Key < top[j].Key
+	})
+	if n >= 0 && n < len(top) {
+		top = top[:n]
+	}
+	return top
+}
+
+func NewUsageCollector(window time.Duration) *UsageCollector {
+	return &UsageCollector{
+		window:  window,
+		buckets: map[int64]*UsageStats{},
+	}
+}
+
+// UsageCollector keeps usage statistics in time buckets of a fixed window so
+// counts can be reported for arbitrary ranges, like the last week.
+type UsageCollector struct {
+	sync.Mutex
+	window  time.Duration
+	buckets map[int64]*UsageStats
+}
+
+// UsageBucket holds the statistics of messages sent in [Start, Start+window).
+type UsageBucket struct {
+	Start time.Time
+	Stats *UsageStats
+}
+
+func (c *UsageCollector) bucket(t time.Time) int64 {
+	return t.Truncate(c.window).UnixNano()
+}
+
+// Add counts a message sent at t.
+func (c *UsageCollector) Add(t time.Time, input string, msg *Span) {
+	c.Lock()
+	defer c.Unlock()
+
+	k := c.bucket(t)
+	s, ok := c.buckets[k]
+	if !ok {
+		s = NewUsageStats()
+		c.buckets[k] = s
+	}
+	s.Add(input, msg)
+}
+
+// Buckets returns the non-empty buckets in chronological order.
+func (c *UsageCollector) Buckets() []UsageBucket {
+	c.Lock()
+	defer c.Unlock()
+
+	buckets := make([]UsageBucket, 0, len(c.buckets))
+	for k, s := range c.buckets {
+		buckets = append(buckets, UsageBucket{time.Unix(0, k).UTC(), s})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Before(buckets[j].Start)
+	})
+	return buckets
+}
+
+// Range returns the combined statistics of the buckets starting in
+// [from, to).
+func (c *UsageCollector) Range(from, to time.Time) *UsageStats {
+	c.Lock()
+	defer c.Unlock()
+
+	s := NewUsageStats()
+	for k, b := range c.buckets {
+		if start := time.Unix(0, k); !start.Before(from) && start.Before(to) {
+			s.Merge(b)
+		}
+	}
+	return s
+}
+
+// Merge adds the buckets of o, which is typically a collector of another
+// shard, to c. Buckets are rebucketed if the windows differ, which is only
+// exact when o's window is a multiple of c's.
+func (c *UsageCollector) Merge(o *UsageCollector) {
+	buckets := o.Buckets()
+
+	c.Lock()
+	defer c.Unlock()
+
+	for _, b := range buckets {
+		k := c.bucket(b.Start)
+		s, ok := c.buckets[k]
+		if !ok {
+			s = NewUsageStats()
+			c.buckets[k] = s
+		}
+		s.Merge(b.Stats)
+	}
+}
+
+// Prune drops the buckets that start before t.
+func (c *UsageCollector) Prune(t time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	for k := range c.buckets {
+		if time.Unix(0, k).Before(t) {
+			delete(c.buckets, k)
+		}
+	}
+}
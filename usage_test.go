@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func addUsage(ctx *ParserContext, s *UsageStats, inputs ...string) {
+	for _, input := range inputs {
+		s.Add(input, NewParser(ctx, NewLexer(input)).ParseMessage())
+	}
+}
+
+func TestUsageStats(t *testing.T) {
+	ctx := newTestParserContext()
+	s := NewUsageStats()
+	addUsage(
+		ctx,
+		s,
+		"PEPE PEPE:wide:spin CuckCrab:wide",
+		"@abeous hi abeous WRXST",
+		"nsfw https://WWW.Example.com/x www.example.com/y `PEPE abeous`",
+		"> nsfl ||PEPE||",
+	)
+
+	expected := &UsageStats{
+		Messages:       4,
+		Emotes:         map[string]int{"PEPE": 3, "CuckCrab": 1},
+		PlainEmotes:    map[string]int{"PEPE": 2},
+		ModifiedEmotes: map[string]int{"PEPE": 1, "CuckCrab": 1},
+		Modifiers:      map[string]int{"wide": 2, "spin": 1},
+		Mentions:       map[string]int{"abeous": 1},
+		BareMentions:   map[string]int{"abeous": 1, "wrxst": 1},
+		Tags:           map[string]int{"nsfw": 1, "nsfl": 1},
+		LinkDomains:    map[string]int{"example.com": 2},
+	}
+	if !reflect.DeepEqual(expected, s) {
+		t.Errorf("got %+v expected %+v", s, expected)
+	}
+}
+
+func TestUsageStatsMerge(t *testing.T) {
+	ctx := newTestParserContext()
+	a, b, c := NewUsageStats(), NewUsageStats(), NewUsageStats()
+	addUsage(ctx, a, "PEPE abeous", "nsfw")
+	addUsage(ctx, b, "PEPE:wide @wrxst")
+	addUsage(ctx, c, "PEPE abeous", "nsfw", "PEPE:wide @wrxst")
+
+	a.Merge(b)
+	if !reflect.DeepEqual(c, a) {
+		t.Errorf("got %+v expected %+v", a, c)
+	}
+
+	var z UsageStats
+	z.Merge(&UsageStats{})
+	z.Merge(c)
+	if !reflect.DeepEqual(c, &z) {
+		t.Errorf("zero value merge: got %+v expected %+v", &z, c)
+	}
+}
+
+func TestUsageStatsZeroValue(t *testing.T) {
+	ctx := newTestParserContext()
+	var a UsageStats
+	b := NewUsageStats()
+	addUsage(ctx, &a, "PEPE:wide @abeous nsfw")
+	addUsage(ctx, b, "PEPE:wide @abeous nsfw")
+	if !reflect.DeepEqual(b, &a) {
+		t.Errorf("got %+v expected %+v", &a, b)
+	}
+}
+
+func TestTopUsage(t *testing.T) {
+	counts := map[string]int{"a": 1, "b": 3, "c": 3, "d": 2}
+	if top := TopUsage(counts, 3); !reflect.DeepEqual(top, []UsageCount{{"b", 3}, {"c", 3}, {"d", 2}}) {
+		t.Errorf("unexpected top %v", top)
+	}
+	if top := TopUsage(counts, -1); len(top) != 4 {
+		t.Errorf("unexpected top %v", top)
+	}
+}
+
+func TestUsageCollector(t *testing.T) {
+	ctx := newTestParserContext()
+	t0 := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	add := func(c *UsageCollector, t time.Time, input string) {
+		c.Add(t, input, NewParser(ctx, NewLexer(input)).ParseMessage())
+	}
+
+	a := NewUsageCollector(time.Hour)
+	add(a, t0.Add(10*time.Minute), "PEPE")
+	add(a, t0.Add(50*time.Minute), "PEPE")
+	add(a, t0.Add(2*time.Hour), "CuckCrab")
+
+	b := NewUsageCollector(time.Hour)
+	add(b, t0.Add(30*time.Minute), "PEPE:wide")
+	add(b, t0.Add(time.Hour), "abeous")
+
+	a.Merge(b)
+
+	buckets := a.Buckets()
+	if len(buckets) != 3 {
+		t.Fatalf("expected 3 buckets, got %d", len(buckets))
+	}
+	for i, n := range []int{3, 1, 1} {
+		if start := t0.Add(time.Duration(i) * time.Hour); !buckets[i].Start.Equal(start) || buckets[i].Stats.Messages != n {
+			t.Errorf("bucket %d: unexpected start %s or count %d", i, buckets[i].Start, buckets[i].Stats.Messages)
+		}
+	}
+
+	s := a.Range(t0, t0.Add(2*time.Hour))
+	if s.Messages != 4 || s.Emotes["PEPE"] != 3 || s.BareMentions["abeous"] != 1 || s.Emotes["CuckCrab"] != 0 {
+		t.Errorf("unexpected range stats %+v", s)
+	}
+
+	a.Prune(t0.Add(time.Hour))
+	if s := a.Range(t0, t0.Add(3*time.Hour)); s.Messages != 2 {
+		t.Errorf("expected 2 messages after prune, got %d", s.Messages)
+	}
+}
@@ -0,0 +1,88 @@
+// Runs the JSON AST fixtures shared with the Go tests against the wasm build.
+//
+//	GOOS=js GOARCH=wasm go build -o parser.wasm ./wasm
+//	node wasm/harness.js parser.wasm testdata/wasm/fixtures.json
+//
+// wasm_exec.js is loaded from the Go installation, or from WASM_EXEC when set
+// (e.g. to TinyGo's copy).
+
+"use strict";
+
+const assert = require("assert");
+const childProcess = require("child_process");
+const fs = require("fs");
+const path = require("path");
+
+if (process.argv.length < 4) {
+	console.error("usage: node harness.js [wasm binary] [fixtures]");
+	process.exit(2);
+}
+
+function wasmExecPath() {
+	if (process.env.WASM_EXEC) {
+		return path.resolve(process.env.WASM_EXEC);
+	}
+	const goroot = childProcess.execFileSync("go", ["env", "GOROOT"]).toString().trim();
+	for (const dir of ["lib/wasm", "misc/wasm"]) {
+		const p = path.join(goroot, dir, "wasm_exec.js");
+		if (fs.existsSync(p)) {
+			return p;
+		}
+	}
+	throw new Error("wasm_exec.js not found in " + goroot);
+}
+
+globalThis.require = require;
+globalThis.fs = fs;
+globalThis.path = path;
+globalThis.TextEncoder = require("util").TextEncoder;
+globalThis.TextDecoder = require("util").TextDecoder;
+if (!globalThis.performance) {
+	globalThis.performance = require("perf_hooks").performance;
+}
+if (!globalThis.crypto) {
+	globalThis.crypto = require("crypto");
+}
+
+require(wasmExecPath());
+
+function check(result) {
+	if (result && result.error) {
+		throw new Error(result.error);
+	}
+	return result;
+}
+
+async function main() {
+	const fixtures = JSON.parse(fs.readFileSync(process.argv[3]));
+
+	const go = new Go();
+	const { instance } = await WebAssembly.instantiate(fs.readFileSync(process.argv[2]), go.importObject);
+	go.run(instance);
+
+	const api = globalThis.chatParser;
+	const id = check(api.createContext(JSON.stringify(fixtures.Context)));
+	for (const u of fixtures.Updates) {
+		check(api.update(id, JSON.stringify(u)));
+	}
+
+	let failed = 0;
+	for (const test of fixtures.Tests) {
+		const ast = JSON.parse(check(api.parse(id, test.input)));
+		try {
+			assert.deepStrictEqual(ast, test.ast);
+		} catch (err) {
+			failed++;
+			console.error(`FAIL ${test.name}\n${err.message}`);
+		}
+	}
+	api.destroyContext(id);
+
+	console.log(`${fixtures.Tests.length - failed}/${fixtures.Tests.length} fixtures passed`);
+	process.exit(failed === 0 ? 0 : 1);
+}
+
+main().catch((err) => {
+	console.error(err);
+	process.exit(1);
+});
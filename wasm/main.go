@@ -0,0 +1,124 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Command wasm exposes the parser to JavaScript as the global chatParser
+// object. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o parser.wasm ./wasm
+//
+// or with TinyGo using -target wasm. Context values, updates and ASTs cross
+// the boundary as JSON strings and contexts are referred to by the number
+// createContext returns, so the API has no dependency on js.Value
+// conversions TinyGo lacks.
+//
+//	const id = chatParser.createContext(JSON.stringify({Emotes: ["PEPE"]}))
+//	chatParser.update(id, JSON.stringify({op: "insert", index: "nicks", value: "abeous"}))
+//	const ast = JSON.parse(chatParser.parse(id, "PEPE hi abeous"))
+//	chatParser.destroyContext(id)
+//
+// Functions return an {error: "..."} object instead of their result on
+// failure. AST positions are UTF-16 offsets into the parsed string.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"syscall/js"
+
+	parser "github.com/MemeLabs/chat-parser"
+)
+
+var errUnknownContext = errors.New("unknown context")
+
+var (
+	contexts = map[int]*parser.ParserContext{}
+	nextID   = 1
+)
+
+func main() {
+	api := js.Global().Get("Object").New()
+	api.Set("createContext", js.FuncOf(createContext))
+	api.Set("destroyContext", js.FuncOf(destroyContext))
+	api.Set("update", js.FuncOf(update))
+	api.Set("parse", js.FuncOf(parse))
+	js.Global().Set("chatParser", api)
+
+	select {}
+}
+
+func jsError(err error) interface{} {
+	return map[string]interface{}{"error": err.Error()}
+}
+
+func context(args []js.Value) (*parser.ParserContext, error) {
+	if len(args) == 0 {
+		return nil, errUnknownContext
+	}
+	ctx, ok := contexts[args[0].Int()]
+	if !ok {
+		return nil, errUnknownContext
+	}
+	return ctx, nil
+}
+
+// createContext(values string) number
+func createContext(this js.Value, args []js.Value) interface{} {
+	var v parser.ParserContextValues
+	if len(args) != 0 {
+		if err := json.Unmarshal([]byte(args[0].String()), &v); err != nil {
+			return jsError(err)
+		}
+	}
+
+	id := nextID
+	nextID++
+	contexts[id] = parser.NewParserContext(v)
+	return id
+}
+
+// destroyContext(id number)
+func destroyContext(this js.Value, args []js.Value) interface{} {
+	if len(args) != 0 {
+		delete(contexts, args[0].Int())
+	}
+	return nil
+}
+
+// update(id number, update string)
+func update(this js.Value, args []js.Value) interface{} {
+	ctx, err := context(args)
+	if err != nil {
+		return jsError(err)
+	}
+	if len(args) < 2 {
+		return jsError(errors.New("missing update"))
+	}
+
+	var u parser.ContextUpdate
+	if err := json.Unmarshal([]byte(args[1].String()), &u); err != nil {
+		return jsError(err)
+	}
+	if err := ctx.Update(u); err != nil {
+		return jsError(err)
+	}
+	return nil
+}
+
+// parse(id number, input string) string
+func parse(this js.Value, args []js.Value) interface{} {
+	ctx, err := context(args)
+	if err != nil {
+		return jsError(err)
+	}
+	if len(args) < 2 {
+		return jsError(errors.New("missing input"))
+	}
+
+	input := args[1].String()
+	msg := parser.NewParser(ctx, parser.NewLexer(input)).ParseMessage()
+	b, err := parser.MarshalNodeJSON(msg, parser.UTF16Offsets(input))
+	if err != nil {
+		return jsError(err)
+	}
+	return string(b)
+}
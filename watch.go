@@ -0,0 +1,276 @@
+package parser
+
+import (
+	"sort"
+	"sync"
+	"unicode"
+)
+
+// WatchMatch is a watched word found in a message.
+type WatchMatch struct {
+	Owner string
+	// Word is the normalized form of the watched word.
+	Word   string
+	TokPos int
+	TokEnd int
+}
+
+func NewWatchList(lists map[string][]string) *WatchList {
+	w := &WatchList{lists: map[string][][]rune{}}
+	for owner, words := range lists {
+		w.Set(owner, words)
+	}
+	return w
+}
+
+// WatchList matches the highlight words of many users against messages in a
+// single pass. Words are case insensitive and match whole words of the
+// visible text outside of code; runs of spaces and punctuation in a word
+// match any run of separators. Nicks, links and channel references are never
+// matched.
+type WatchList struct {
+	sync.Mutex
+	lists map[string][][]rune
+	// m is rebuilt by the next Match after the lists change.
+	m *watchMatcher
+}
+
+// Set replaces the watched words of owner. An empty list removes the owner.
+func (w *WatchList) Set(owner string, words []string) {
+	var ws [][]rune
+	for _, word := range words {
+		if n := normalizeWatchWord([]rune(word)); len(n) != 0 {
+			ws = append(ws, n)
+		}
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	if len(ws) == 0 {
+		delete(w.lists, owner)
+	} else {
+		w.lists[owner] = ws
+	}
+	w.m = nil
+}
+
+func (w *WatchList) Remove(owner string) {
+	w.Set(owner, nil)
+}
+
+// normalizeWatchWord lower cases word, trims separators from its ends and
+// replaces runs of separators inside it with a single space.
+func normalizeWatchWord(word []rune) (n []rune) {
+	word = runeSliceToLower(word, nil)
+	sep := false
+	for _, r := range word {
+		if !isWordRune(r) {
+			sep = len(n) != 0
+			continue
+		}
+		if sep {
+			n = append(n, ' ')
+			sep = false
+		}
+		n = append(n, r)
+	}
+	return
+}
+
+func watchTextMode(n Node) textMode {
+	switch n := n.(type) {
+	case *Nick, *Link, *ChannelRef:
+		return textBreak
+	case *Emoji:
+		return textSeparate
+	case *Span:
+		if n.Type == SpanCode {
+			return textBreak
+		}
+	}
+	return textInclude
+}
+
+// Match returns the watched words found in msg, which was parsed from input,
+// ordered by position and then owner.
+func (w *WatchList) Match(input string, msg *Span) (matches []WatchMatch) {
+	text, pos := watchText(newTextView([]rune(input), msg, watchTextMode))
+
+	w.Lock()
+	defer w.Unlock()
+
+	if w.m == nil {
+		w.m = newWatchMatcher(w.lists)
+	}
+	w.m.match(text, func(p *watchPattern, i int) {
+		start := i - len(p.word) + 1
+		if start > 0 && isWordRune(text[start-1]) || i+1 < len(text) && isWordRune(text[i+1]) {
+			return
+		}
+		for _, owner := range p.owners {
+			matches = append(matches, WatchMatch{
+				Owner:  owner,
+				Word:   string(p.word),
+				TokPos: pos[start],
+				TokEnd: pos[i] + 1,
+			})
+		}
+	})
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].TokPos != matches[j].TokPos {
+			return matches[i].TokPos < matches[j].TokPos
+		}
+		if matches[i].TokEnd != matches[j].TokEnd {
+			return matches[i].TokEnd < matches[j].TokEnd
+		}
+		return matches[i].Owner < matches[j].Owner
+	})
+	return
+}
+
+// watchText lower cases the view and collapses runs of separators into a
+// single space so it can be matched against normalized words. Breaks are
+// kept so words cannot span them.
+func watchText(v *textView) (text []rune, pos []int) {
+	text = make([]rune, 0, len(v.text))
+	pos = make([]int, 0, len(v.pos))
+	for i := 0; i < len(v.text); i++ {
+		r := v.text[i]
+		switch {
+		case r == textBreakRune:
+		case isWordRune(r):
+			r = unicode.ToLower(r)
+		default:
+			r = ' '
+			if len(text) != 0 && text[len(text)-1] == ' ' {
+				continue
+			}
+		}
+		text = append(text, r)
+		pos = append(pos, v.pos[i])
+	}
+	return
+}
+
+type watchPattern struct {
+	word   []rune
+	owners []string
+}
+
+type watchNode struct {
+	next map[rune]int32
+	fail int32
+	// out is the pattern ending at this node or -1. dict is the nearest node
+	// on the fail chain with an output or -1.
+	out  int32
+	dict int32
+}
+
+// watchMatcher is an Aho-Corasick automaton over the normalized words of all
+// watch lists. Words shared by several owners are stored once.
+type watchMatcher struct {
+	nodes    []watchNode
+	patterns []watchPattern
+}
+
+func newWatchMatcher(lists map[string][][]rune) *watchMatcher {
+	m := &watchMatcher{nodes: []watchNode{{out: -1, dict: -1}}}
+
+	owners := make([]string, 0, len(lists))
+	for owner := range lists {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+
+	for _, owner := range owners {
+		for _, word := range lists[owner] {
+			m.insert(word, owner)
+		}
+	}
+	m.link()
+	return m
+}
+
+func (m *watchMatcher) insert(word []rune, owner string) {
+	var s int32
+	for _, r := range word {
+		t, ok := m.nodes[s].next[r]
+		if !ok {
+			if m.nodes[s].next == nil {
+				m.nodes[s].next = map[rune]int32{}
+			}
+			t = int32(len(m.nodes))
+			m.nodes[s].next[r] = t
+			m.nodes = append(m.nodes, watchNode{out: -1, dict: -1})
+		}
+		s = t
+	}
+
+	if m.nodes[s].out == -1 {
+		m.nodes[s].out = int32(len(m.patterns))
+		m.patterns = append(m.patterns, watchPattern{word: word})
+	}
+	p := &m.patterns[m.nodes[s].out]
+	if n := len(p.owners); n == 0 || p.owners[n-1] != owner {
+		p.owners = append(p.owners, owner)
+	}
+}
+
+// link sets the fail and dictionary links breadth first.
+func (m *watchMatcher) link() {
+	queue := make([]int32, 0, len(m.nodes))
+	for _, t := range m.nodes[0].next {
+		queue = append(queue, t)
+	}
+	for len(queue) != 0 {
+		s := queue[0]
+		queue = queue[1:]
+
+		for r, t := range m.nodes[s].next {
+			f := m.nodes[s].fail
+			for {
+				if ft, ok := m.nodes[f].next[r]; ok {
+					f = ft
+					break
+				}
+				if f == 0 {
+					break
+				}
+				f = m.nodes[f].fail
+			}
+			m.nodes[t].fail = f
+			if m.nodes[f].out != -1 {
+				m.nodes[t].dict = f
+			} else {
+				m.nodes[t].dict = m.nodes[f].dict
+			}
+			queue = append(queue, t)
+		}
+	}
+}
+
+// match calls fn with every pattern occurring in text and the index of its
+// last rune.
+func (m *watchMatcher) match(text []rune, fn func(p *watchPattern, i int)) {
+	var s int32
+	for i, r := range text {
+		for {
+			if t, ok := m.nodes[s].next[r]; ok {
+				s = t
+				break
+			}
+			if s == 0 {
+				break
+			}
+			s = m.nodes[s].fail
+		}
+
+		for o := s; o != -1; o = m.nodes[o].dict {
+			if out := m.nodes[o].out; out != -1 {
+				fn(&m.patterns[out], i)
+			}
+		}
+	}
+}
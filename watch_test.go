@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestWatchList(t *testing.T) {
+	ctx := newTestParserContext()
+	w := NewWatchList(map[string][]string{
+		"abeous": {"Pizza", "ice  cream", "a"},
+		"wrxst":  {"pizza", "pepe", "--"},
+		"bob":    {"crab", "rust"},
+	})
+
+	cases := []struct {
+		name     string
+		input    string
+		expected []WatchMatch
+	}{
+		{"none", "hello there", nil},
+		{"shared", "PIZZA!", []WatchMatch{
+			{Owner: "abeous", Word: "pizza", TokPos: 0, TokEnd: 5},
+			{Owner: "wrxst", Word: "pizza", TokPos: 0, TokEnd: 5},
+		}},
+		{"word boundary", "pizzas rusty crabs a", []WatchMatch{
+			{Owner: "abeous", Word: "a", TokPos: 19, TokEnd: 20},
+		}},
+		{"separators", "ice, cream", []WatchMatch{
+			{Owner: "abeous", Word: "ice cream", TokPos: 0, TokEnd: 10},
+		}},
+		{"emote", "PEPE:wide", []WatchMatch{
+			{Owner: "wrxst", Word: "pepe", TokPos: 0, TokEnd: 4},
+		}},
+		{"spoiler", "||ice|| cream", []WatchMatch{
+			{Owner: "abeous", Word: "ice cream", TokPos: 2, TokEnd: 13},
+		}},
+		{"code", "`pizza` rust", []WatchMatch{
+			{Owner: "bob", Word: "rust", TokPos: 8, TokEnd: 12},
+		}},
+		{"code break", "ice `x` cream", nil},
+		{"nick", "abeous crab @wrxst", []WatchMatch{
+			{Owner: "bob", Word: "crab", TokPos: 7, TokEnd: 11},
+		}},
+		{"link", "https://pizza.com", nil},
+	}
+
+	for _, c := range cases {
+		msg := NewParser(ctx, NewLexer(c.input)).ParseMessage()
+		if matches := w.Match(c.input, msg); !reflect.DeepEqual(c.expected, matches) {
+			t.Errorf("%s: got %s expected %s", c.name, spew.Sdump(matches), spew.Sdump(c.expected))
+		}
+	}
+}
+
+func TestWatchListUpdate(t *testing.T) {
+	ctx := newTestParserContext()
+	w := NewWatchList(nil)
+	input := "pizza party"
+	msg := NewParser(ctx, NewLexer(input)).ParseMessage()
+
+	w.Set("abeous", []string{"pizza"})
+	if matches := w.Match(input, msg); len(matches) != 1 {
+		t.Errorf("expected 1 match, got %d", len(matches))
+	}
+	w.Set("abeous", []string{"party"})
+	if matches := w.Match(input, msg); len(matches) != 1 || matches[0].Word != "party" {
+		t.Errorf("unexpected matches %v", matches)
+	}
+	w.Remove("abeous")
+	if matches := w.Match(input, msg); len(matches) != 0 {
+		t.Errorf("unexpected matches %v", matches)
+	}
+}
+
+// TestWatchMatcher compares the automaton with a naive search.
+func TestWatchMatcher(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randomText := func(n int) []rune {
+		b := make([]rune, n)
+		for i := range b {
+			b[i] = rune("ab c"[r.Intn(4)])
+		}
+		return b
+	}
+
+	for i := 0; i < 200; i++ {
+		lists := map[string][][]rune{}
+		for j := 0; j < 1+r.Intn(20); j++ {
+			owner := string(rune('A' + r.Intn(5)))
+			if word := normalizeWatchWord(randomText(1 + r.Intn(5))); len(word) != 0 {
+				lists[owner] = append(lists[owner], word)
+			}
+		}
+		m := newWatchMatcher(lists)
+		text := randomText(50)
+
+		got := map[string]int{}
+		m.match(text, func(p *watchPattern, i int) {
+			got[string(p.word)] += len(p.owners)
+		})
+
+		expected := map[string]int{}
+		for _, words := range lists {
+			seen := map[string]bool{}
+			for _, word := range words {
+				if s := string(word); !seen[s] {
+					seen[s] = true
+					if n := countOverlapping(string(text), s); n != 0 {
+						expected[s] += n
+					}
+				}
+			}
+		}
+
+		if !reflect.DeepEqual(expected, got) {
+			t.Fatalf("text %q lists %q: got %v expected %v", string(text), lists, got, expected)
+		}
+	}
+}
+
+// countOverlapping returns the number of possibly overlapping occurrences
+// of sub in s.
+func countOverlapping(s, sub string) (n int) {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if s[i:i+len(sub)] == sub {
+			n++
+		}
+	}
+	return
+}